@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// board holds the cells of a game, indexed as board[row][column].
+type board [][]int
+
+func newBoard() board {
+	return board{
+		{0, 0, 0},
+		{0, 0, 0},
+		{0, 0, 0},
+	}
+}
+
+// Equal reports whether b and other hold the same cells.
+func (b board) Equal(other board) bool {
+	if len(b) != len(other) {
+		return false
+	}
+	for i := range b {
+		if len(b[i]) != len(other[i]) {
+			return false
+		}
+		for j := range b[i] {
+			if b[i][j] != other[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CountEmpty returns the number of cells of b that haven't been played.
+func (b board) CountEmpty() int {
+	n := 0
+	for _, row := range b {
+		for _, c := range row {
+			if c == 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// cells returns the number of cells of b.
+func (b board) cells() int {
+	n := 0
+	for _, row := range b {
+		n += len(row)
+	}
+	return n
+}
+
+// gridSize is the width and height of b drawn by player.grid, a box-drawing
+// line around and between the cells of the size set by the grid style.
+func (b board) gridSize(style string) (int, int) {
+	cols := 0
+	if len(b) > 0 {
+		cols = len(b[0])
+	}
+	w, h := cellSize(style)
+	return (w+1)*cols + 1, (h+1)*len(b) + 1
+}
+
+// clone returns a deep copy of b, so that model copies don't share cells.
+func (b board) clone() board {
+	c := make(board, len(b))
+	for i := range b {
+		c[i] = append([]int(nil), b[i]...)
+	}
+	return c
+}
+
+// boardRunes encode the cells of a board in text, see parseBoard.
+var boardRunes = map[int]rune{
+	1:  'o',
+	-1: 'x',
+	0:  '.',
+}
+
+// parseBoard reads a 3x3 board written as rows separated by "|", with "o" for
+// ○, "x" for × and "." for an empty cell, like "xo.|..x|o..".
+func parseBoard(s string) (board, error) {
+	rows := strings.Split(s, "|")
+	if len(rows) != 3 {
+		return nil, fmt.Errorf("board %q must have 3 rows", s)
+	}
+	b := make(board, len(rows))
+	for i, row := range rows {
+		if len(row) != 3 {
+			return nil, fmt.Errorf("board %q: row %d must have 3 cells", s, i+1)
+		}
+		b[i] = make([]int, len(row))
+		for j, r := range row {
+			switch r {
+			case 'o', 'O':
+				b[i][j] = 1
+			case 'x', 'X':
+				b[i][j] = -1
+			case '.':
+			default:
+				return nil, fmt.Errorf("board %q: %q is not one of o, x, .", s, r)
+			}
+		}
+	}
+	return b, nil
+}
+
+// formatBoard writes b in the encoding read by parseBoard. The strike-through
+// marks of a finished game are written as empty cells.
+func formatBoard(b board) string {
+	rows := make([]string, len(b))
+	for i, row := range b {
+		var sb strings.Builder
+		for _, c := range row {
+			r, ok := boardRunes[c]
+			if !ok {
+				r = boardRunes[0]
+			}
+			sb.WriteRune(r)
+		}
+		rows[i] = sb.String()
+	}
+	return strings.Join(rows, "|")
+}
+
+// UnmarshalJSON reads a board either as rows of cell values or as a string
+// in the encoding of parseBoard.
+func (b *board) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := parseBoard(s)
+		if err != nil {
+			return err
+		}
+		*b = v
+		return nil
+	}
+	var cells [][]int
+	if err := json.Unmarshal(data, &cells); err != nil {
+		return err
+	}
+	*b = cells
+	return nil
+}
+
+// line is a set of cells, as row and column, that wins the game once a
+// single player owns all of them.
+type line [][2]int
+
+var (
+	rowLines = []line{
+		{{0, 0}, {0, 1}, {0, 2}},
+		{{1, 0}, {1, 1}, {1, 2}},
+		{{2, 0}, {2, 1}, {2, 2}},
+	}
+	columnLines = []line{
+		{{0, 0}, {1, 0}, {2, 0}},
+		{{0, 1}, {1, 1}, {2, 1}},
+		{{0, 2}, {1, 2}, {2, 2}},
+	}
+	diagonalLines = []line{
+		{{0, 0}, {1, 1}, {2, 2}},
+		{{0, 2}, {1, 1}, {2, 0}},
+	}
+)
+
+// winRules are the named sets of winning lines.
+var winRules = map[string][]line{
+	"standard":    concatLines(rowLines, columnLines, diagonalLines),
+	"nodiagonals": concatLines(rowLines, columnLines),
+	"diagonals":   diagonalLines,
+}
+
+// winLines are the lines counting as a win under the configured rules.
+var winLines = winRules["standard"]
+
+func concatLines(sets ...[]line) []line {
+	var all []line
+	for _, s := range sets {
+		all = append(all, s...)
+	}
+	return all
+}
+
+// owner returns the player owning every cell of l on b, or 0.
+func (l line) owner(b board) int {
+	p := b[l[0][0]][l[0][1]]
+	if p != 1 && p != -1 {
+		return 0
+	}
+	for _, c := range l[1:] {
+		if b[c[0]][c[1]] != p {
+			return 0
+		}
+	}
+	return p
+}
+
+// mark is the board value drawing the strike-through of l, picked by the
+// direction from its first to its second cell.
+func (l line) mark() int {
+	dx, dy := l[1][0]-l[0][0], l[1][1]-l[0][1]
+	switch {
+	case dx == 0:
+		return 2
+	case dy == 0:
+		return 3
+	case dx == dy:
+		return 4
+	}
+	return 5
+}
+
+// validate checks that l has at least two distinct cells on a 3x3 board.
+func (l line) validate() error {
+	if len(l) < 2 {
+		return errors.New("a line needs at least two cells")
+	}
+	seen := map[[2]int]bool{}
+	for _, c := range l {
+		if c[0] < 0 || c[0] > 2 || c[1] < 0 || c[1] > 2 {
+			return fmt.Errorf("cell %v is off the board", c)
+		}
+		if seen[c] {
+			return fmt.Errorf("cell %v is repeated", c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// completedLines returns the winning lines of b owned by a single player.
+func completedLines(b board) []line {
+	var won []line
+	for _, l := range winLines {
+		if l.owner(b) != 0 {
+			won = append(won, l)
+		}
+	}
+	return won
+}
+
+// winner returns the player owning a winning line of b, or 0 if there is
+// none.
+func winner(b board) int {
+	for _, l := range winLines {
+		if p := l.owner(b); p != 0 {
+			return p
+		}
+	}
+	return 0
+}
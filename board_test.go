@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWinRules(t *testing.T) {
+	corners := []line{{{0, 0}, {0, 2}, {2, 0}, {2, 2}}}
+	center := []line{{{1, 1}, {0, 1}}}
+	for _, tt := range []struct {
+		name  string
+		lines []line
+		board string
+		want  int
+	}{
+		{"standard row", winRules["standard"], "ooo|xx.|...", 1},
+		{"standard diagonal", winRules["standard"], "x.o|.xo|..x", -1},
+		{"nodiagonals row", winRules["nodiagonals"], "x..|xoo|x.o", -1},
+		{"nodiagonals diagonal", winRules["nodiagonals"], "x.o|.xo|..x", 0},
+		{"diagonals row", winRules["diagonals"], "ooo|xx.|...", 0},
+		{"diagonals antidiagonal", winRules["diagonals"], "x.o|.o.|ox.", 1},
+		{"corners", corners, "o.o|xx.|o.o", 1},
+		{"corners row", corners, "ooo|xx.|...", 0},
+		{"two cells", center, "xo.|.o.|x..", 1},
+		{"two cells column", center, "xo.|xo.|x..", 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestGame(t)
+			winLines = tt.lines
+			b, err := parseBoard(tt.board)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := winner(b); got != tt.want {
+				t.Errorf("winner of %s is %d, want %d", tt.board, got, tt.want)
+			}
+			if won := len(completedLines(b)) > 0; won != (tt.want != 0) {
+				t.Errorf("completedLines of %s found a line %v, want %v", tt.board, won, tt.want != 0)
+			}
+		})
+	}
+}
+
+func TestCustomLinesPlay(t *testing.T) {
+	g := newTestGame(t)
+	winLines = []line{{{0, 0}, {0, 2}, {2, 0}, {2, 2}}}
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	// bob's middle column wins nothing, alice's corners do
+	play(alice, bob, "q", "w", "e", "s", "z", "x")
+	if g.gs.m.gameOver {
+		t.Fatalf("the game ended before the corners were taken:\n%s", alice.view())
+	}
+	alice.press("c")
+	if !g.gs.m.gameOver || g.gs.m.winner != 1 {
+		t.Errorf("gameOver %v winner %d, want alice winning on the corners:\n%s", g.gs.m.gameOver, g.gs.m.winner, alice.view())
+	}
+}
+
+func TestLineValidate(t *testing.T) {
+	for _, tt := range []struct {
+		l  line
+		ok bool
+	}{
+		{line{{0, 0}, {1, 1}, {2, 2}}, true},
+		{line{{0, 0}, {2, 2}}, true},
+		{line{{0, 0}}, false},
+		{line{{0, 0}, {0, 3}}, false},
+		{line{{-1, 0}, {0, 0}}, false},
+		{line{{1, 1}, {1, 1}}, false},
+	} {
+		if err := tt.l.validate(); (err == nil) != tt.ok {
+			t.Errorf("validate(%v) = %v, want ok %v", tt.l, err, tt.ok)
+		}
+	}
+}
+
+func TestDoubleLineScoresOnce(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	// alice's last move on q completes the top row and the left column
+	play(alice, bob, "w", "s", "e", "d", "a", "x", "z", "c", "q")
+	struck := 0
+	for _, row := range g.gs.m.board {
+		for _, c := range row {
+			if c > 1 {
+				struck++
+			}
+		}
+	}
+	if struck != 5 {
+		t.Errorf("%d cells are struck through, want the 5 of both lines:\n%s", struck, alice.view())
+	}
+	for _, s := range []*testSession{alice, bob} {
+		if !s.m.gameOver || s.m.winner != 1 {
+			t.Errorf("%s: gameOver %v winner %d, want a win of alice", s.m.me().name, s.m.gameOver, s.m.winner)
+		}
+		if s.m.players[0].score != 1 || s.m.players[1].score != 0 {
+			t.Errorf("%s: scores %d:%d after two lines at once, want 1:0", s.m.me().name, s.m.players[0].score, s.m.players[1].score)
+		}
+	}
+	if ps, _ := scores.GetPlayer("user:alice"); ps.Wins != 1 {
+		t.Errorf("alice's record has %d wins, want 1", ps.Wins)
+	}
+}
+
+func TestBoardEncodingRoundTrip(t *testing.T) {
+	for _, s := range []string{"...|...|...", "xo.|..x|o..", "ooo|xxx|ooo", "x.o|.o.|o.x"} {
+		b, err := parseBoard(s)
+		if err != nil {
+			t.Fatalf("parseBoard(%q): %v", s, err)
+		}
+		if got := formatBoard(b); got != s {
+			t.Errorf("formatBoard(parseBoard(%q)) = %q", s, got)
+		}
+	}
+	// upper case reads as lower case, strike-through marks write as empty
+	b, err := parseBoard("XO.|...|...")
+	if err != nil || b[0][0] != -1 || b[0][1] != 1 {
+		t.Fatalf("parseBoard of upper case = %v, %v", b, err)
+	}
+	b[1][0], b[1][1] = 2, 4
+	if got := formatBoard(b); got != "xo.|...|..." {
+		t.Errorf("formatBoard with marks = %q", got)
+	}
+}
+
+func TestParseBoardMalformed(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"...|...",
+		"...|...|...|...",
+		"..|...|...",
+		"....|...|...",
+		"...|.a.|...",
+		"...|...|..-",
+		"xo.,..x,o..",
+		"ох.|...|...", // Cyrillic, not Latin
+	} {
+		if b, err := parseBoard(s); err == nil {
+			t.Errorf("parseBoard(%q) = %v, want an error", s, b)
+		}
+	}
+}
+
+func TestBoardUnmarshalJSON(t *testing.T) {
+	for _, data := range []string{`"xo.|..x|o.."`, `[[-1,1,0],[0,0,-1],[1,0,0]]`} {
+		var b board
+		if err := json.Unmarshal([]byte(data), &b); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if got := formatBoard(b); got != "xo.|..x|o.." {
+			t.Errorf("%s reads as %q", data, got)
+		}
+	}
+	var b board
+	if err := json.Unmarshal([]byte(`"xo.|..x"`), &b); err == nil {
+		t.Errorf("a malformed board reads as %v", b)
+	}
+}
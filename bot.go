@@ -0,0 +1,246 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// strategy picks the move of the bot playing piece me on b, given the moves
+// so far. intn draws a random number in [0, n).
+type strategy func(b board, me int, moves []move, intn func(n int) int) (x int, y int)
+
+// strategies are the bots selectable with -bot.
+var strategies = map[string]strategy{
+	"random":  randomMove,
+	"mirror":  mirrorMove,
+	"greedy":  greedyMove,
+	"minimax": minimaxMove,
+}
+
+// difficulties are the strategies a player picks from in the settings, from
+// easy to hard.
+var difficulties = []string{"random", "mirror", "greedy", "minimax"}
+
+// emptyCells lists the cells of b that can still be played.
+func emptyCells(b board) [][2]int {
+	var cells [][2]int
+	for x := range b {
+		for y := range b[x] {
+			if b[x][y] == 0 {
+				cells = append(cells, [2]int{x, y})
+			}
+		}
+	}
+	return cells
+}
+
+func randomMove(b board, me int, moves []move, intn func(n int) int) (int, int) {
+	cells := emptyCells(b)
+	c := cells[intn(len(cells))]
+	return c[0], c[1]
+}
+
+// mirrorMove answers the last move with its reflection through the center
+// of the board, or any empty cell once that is taken.
+func mirrorMove(b board, me int, moves []move, intn func(n int) int) (int, int) {
+	for i := len(moves) - 1; i >= 0; i-- {
+		if moves[i].x == pass {
+			continue
+		}
+		x, y := reflect(b, moves[i].x, moves[i].y)
+		if b[x][y] == 0 {
+			return x, y
+		}
+		break
+	}
+	return randomMove(b, me, moves, intn)
+}
+
+// greedyMove wins if it can and blocks the opponent's win if it must,
+// otherwise it takes the center or any empty cell.
+func greedyMove(b board, me int, moves []move, intn func(n int) int) (int, int) {
+	b = b.clone()
+	for _, p := range []int{me, -me} {
+		for _, c := range emptyCells(b) {
+			b[c[0]][c[1]] = p
+			w := winner(b)
+			b[c[0]][c[1]] = 0
+			if w == p {
+				return c[0], c[1]
+			}
+		}
+	}
+	if cx, cy := len(b)/2, len(b[0])/2; b[cx][cy] == 0 {
+		return cx, cy
+	}
+	return randomMove(b, me, moves, intn)
+}
+
+// minimaxMove plays perfectly, picking at random among the best moves.
+// Moves leading to equivalent positions under the board's symmetries
+// keeping the winning lines are searched once, and all of them are candidates if they score best, so the
+// bot varies its openings.
+func minimaxMove(b board, me int, moves []move, intn func(n int) int) (int, int) {
+	return minimaxSearch(b, me, intn, nil)
+}
+
+// minimaxSearch is minimaxMove sending every move that scores better than
+// the ones searched before it on better, unless that is nil.
+func minimaxSearch(b board, me int, intn func(n int) int, better chan<- [2]int) (int, int) {
+	b = b.clone()
+	seen := newSearched(b)
+	scores := make(map[string]int)
+	best, bestScore := [][2]int(nil), -2*len(emptyCells(b))-2
+	for _, c := range emptyCells(b) {
+		b[c[0]][c[1]] = me
+		key := canonical(b, seen.syms)
+		score, ok := scores[key]
+		if !ok {
+			score = -negamax(b, -me, seen)
+			scores[key] = score
+		}
+		b[c[0]][c[1]] = 0
+		switch {
+		case score > bestScore:
+			best, bestScore = [][2]int{c}, score
+			if better != nil {
+				better <- c
+			}
+		case score == bestScore:
+			best = append(best, c)
+		}
+	}
+	c := best[intn(len(best))]
+	return c[0], c[1]
+}
+
+// negamax scores b for the player p to move: positive if p wins, sooner
+// wins scoring higher, negative if p loses and 0 for a draw. seen holds the
+// positions searched already.
+func negamax(b board, p int, seen *searched) int {
+	key := canonical(b, seen.syms)
+	if score, ok := seen.scores[key]; ok {
+		return score
+	}
+	empty := emptyCells(b)
+	if w := winner(b); w != 0 {
+		// the player who just moved won
+		return -(len(empty) + 1)
+	}
+	if len(empty) == 0 {
+		return 0
+	}
+	best := -len(empty) - 1
+	for _, c := range empty {
+		b[c[0]][c[1]] = p
+		if score := -negamax(b, -p, seen); score > best {
+			best = score
+		}
+		b[c[0]][c[1]] = 0
+	}
+	seen.scores[key] = best
+	return best
+}
+
+// reflect returns the cell opposite x, y through the center of b. The
+// center cell reflects onto itself.
+func reflect(b board, x int, y int) (int, int) {
+	return len(b) - 1 - x, len(b[x]) - 1 - y
+}
+
+// botSeat is the seat kept for the bot, if -bot is set.
+const botSeat = 1
+
+// botTurn reports whether the session has to play the bot's move. The
+// session of the human player does, so that the bot moves once.
+func (m model) botTurn() bool {
+	return cfg.Bot != "" && !m.spectator && m.self != botSeat && !m.gameOver &&
+		m.flip == 0 && !m.waiting() && seat(m.currentPlayer) == botSeat
+}
+
+// tickBot plays the bot's move once it thought for -botdelay.
+func (m *model) tickBot() tea.Cmd {
+	if !m.botTurn() {
+		m.botLeft = 0
+		return nil
+	}
+	t := turnTimer{game: m.record.started, moves: len(m.record.moves), player: m.currentPlayer}
+	if t != m.botMove {
+		m.botMove = t
+		m.botLeft = 0
+		if cfg.BotDelay > 0 {
+			m.botLeft = ticks(time.Duration(cfg.BotDelay))
+			return m.think()
+		}
+	} else {
+		m.botLeft--
+	}
+	if m.botLeft > 0 {
+		return nil
+	}
+	x, y := strategies[m.botStrategy()](m.board, m.currentPlayer, m.record.moves, m.gs.Intn)
+	if !m.gs.Play(m, x, y) {
+		return nil
+	}
+	return m.finish()
+}
+
+// Intn draws a random number in [0, n) from the game's rng.
+func (gs *gameState) Intn(n int) int {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return rng.Intn(n)
+}
+
+// thinkThrottle is how often at most the bot's best move so far is
+// broadcast, see -botthinking.
+const thinkThrottle = 100 * time.Millisecond
+
+// thinkMsg is the best move the bot found so far in the game at the given
+// number of moves, shown to spectators.
+type thinkMsg struct {
+	game  string
+	moves int
+	x, y  int
+}
+
+// think searches the bot's move in the background, for spectators to follow
+// the best move found as the search goes, at most every thinkThrottle and
+// always the last one. The bot plays its own search once it is done
+// thinking. Only minimax searches long enough to follow.
+func (m model) think() tea.Cmd {
+	if !cfg.BotThinking || m.botStrategy() != "minimax" {
+		return nil
+	}
+	b, me, gs := m.board.clone(), m.currentPlayer, m.gs
+	game, moves := m.record.id, len(m.record.moves)
+	return func() tea.Msg {
+		better := make(chan [2]int)
+		go func() {
+			defer close(better)
+			minimaxSearch(b, me, func(int) int { return 0 }, better)
+		}()
+		var sent time.Time
+		var last *thinkMsg
+		for c := range better {
+			last = &thinkMsg{game: game, moves: moves, x: c[0], y: c[1]}
+			if time.Since(sent) >= thinkThrottle {
+				gs.BroadcastMessage(*last)
+				sent, last = time.Now(), nil
+			}
+		}
+		if last != nil {
+			gs.BroadcastMessage(*last)
+		}
+		return nil
+	}
+}
+
+// thought reports whether the spectator is shown the bot's best move so far
+// on x, y.
+func (m model) thought(x, y int) bool {
+	t := m.thinking
+	return m.spectator && t.game == m.record.id && t.moves == len(m.record.moves) &&
+		!m.gameOver && t.x == x && t.y == y
+}
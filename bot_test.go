@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBotDelay(t *testing.T) {
+	for _, delay := range []time.Duration{0, 500 * time.Millisecond} {
+		g := newTestGame(t)
+		cfg.Bot = "random"
+		cfg.BotDelay = duration(delay)
+		alice := g.connect("alice")
+		alice.press("q")
+		alice.update(tickMsg(time.Now()))
+		g.settle()
+		moved := len(g.gs.m.record.moves) == 2
+		if moved != (delay == 0) {
+			t.Errorf("delay %v: the bot moved %v on the first tick", delay, moved)
+		}
+		if thinking := strings.Contains(alice.view(), "is thinking"); thinking == moved {
+			t.Errorf("delay %v: the bot is shown thinking %v after it moved %v:\n%s", delay, thinking, moved, alice.view())
+		}
+		for i := 0; !moved && i < ticks(delay); i++ {
+			alice.update(tickMsg(time.Now()))
+			g.settle()
+			moved = len(g.gs.m.record.moves) == 2
+		}
+		if !moved {
+			t.Errorf("delay %v: the bot didn't move once it passed", delay)
+		}
+	}
+}
+
+func TestReflect(t *testing.T) {
+	b := newBoard()
+	for x := range b {
+		for y := range b[x] {
+			rx, ry := reflect(b, x, y)
+			if rx != 2-x || ry != 2-y {
+				t.Errorf("(%d,%d) reflects to (%d,%d), want (%d,%d)", x, y, rx, ry, 2-x, 2-y)
+			}
+			if bx, by := reflect(b, rx, ry); bx != x || by != y {
+				t.Errorf("(%d,%d) reflects back to (%d,%d)", x, y, bx, by)
+			}
+		}
+	}
+	if x, y := reflect(b, 1, 1); x != 1 || y != 1 {
+		t.Errorf("the center reflects to (%d,%d), want itself", x, y)
+	}
+}
+
+func TestMirrorMove(t *testing.T) {
+	first := func(int) int { return 0 }
+	for _, tt := range []struct {
+		name  string
+		board string
+		moves []move
+		x, y  int
+	}{
+		{"corner", "o..|...|...", []move{{x: 0, y: 0}}, 2, 2},
+		{"edge", ".o.|...|...", []move{{x: 0, y: 1}}, 2, 1},
+		{"past a pass", "..o|...|...", []move{{x: 0, y: 2}, {x: pass, y: pass}}, 2, 0},
+		// the center reflects onto itself, taken: the first free cell
+		{"center", "...|.o.|...", []move{{x: 1, y: 1}}, 0, 0},
+		{"taken", "o..|...|..x", []move{{x: 2, y: 2}, {x: 0, y: 0}}, 0, 1},
+		{"no moves", "...|...|...", nil, 0, 0},
+	} {
+		b, err := parseBoard(tt.board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x, y := mirrorMove(b, -1, tt.moves, first); x != tt.x || y != tt.y {
+			t.Errorf("%s: the mirror plays (%d,%d), want (%d,%d)", tt.name, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestDifficultySetting(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Bot = "random"
+	cfg.BotDelay = 0
+	alice := g.connect("alice")
+	alice.press("2", "4")
+	if !strings.Contains(alice.view(), "bot: random") {
+		t.Fatalf("the settings don't show the bot:\n%s", alice.view())
+	}
+	alice.press("o")
+	if got := alice.m.botStrategy(); got != "mirror" {
+		t.Fatalf("the bot is %q after picking the next difficulty, want mirror", got)
+	}
+	alice.press("esc", "1", "q")
+	alice.update(tickMsg(time.Now()))
+	g.settle()
+	if g.gs.m.board[2][2] != -1 {
+		t.Errorf("the bot didn't mirror alice's corner:\n%s", alice.view())
+	}
+	if p := alice.m.prefs(); p.Difficulty != "mirror" {
+		t.Errorf("the prefs keep the difficulty %q, want mirror", p.Difficulty)
+	}
+	cfg.Ladder = true
+	if got := alice.m.botStrategy(); got != ladder[alice.m.rung] {
+		t.Errorf("the ladder plays %q, want its rung's %q", got, ladder[alice.m.rung])
+	}
+}
+
+func TestMinimaxAsymmetricLines(t *testing.T) {
+	saved := winLines
+	defer func() { winLines = saved }()
+	// only the top row wins, so the bottom row isn't its mirror image
+	winLines = []line{{{0, 0}, {0, 1}, {0, 2}}}
+	b, err := parseBoard("oo.|xx.|oo.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if x, y := minimaxMove(b, 1, nil, rand.Intn); x != 0 || y != 2 {
+			t.Fatalf("run %d: the bot plays (%d,%d), want the winning (0,2)", i+1, x, y)
+		}
+	}
+}
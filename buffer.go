@@ -0,0 +1,27 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// maxBuffered caps the moves kept while an animation plays, enough for every
+// cell of the board.
+const maxBuffered = 9
+
+// buffer keeps the move to cell x, y, made while an animation plays, for
+// drain to make once it ends.
+func (m *model) buffer(x, y int) {
+	if len(m.buffered) < maxBuffered {
+		m.buffered = append(m.buffered, [2]int{x, y})
+	}
+}
+
+// drain makes the moves buffered during the animation just ended, in the
+// order they came in. Those that aren't legal by then are dropped as if
+// made without the animation.
+func (m *model) drain() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, c := range m.buffered {
+		cmds = append(cmds, m.press(c[0], c[1]))
+	}
+	m.buffered = nil
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMovesDuringFlipApplyInOrder(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	g.connect("bob")
+	alice.update(coinFlipMsg{first: alice.m.currentPlayer})
+	if alice.m.flip == 0 {
+		t.Fatal("the coin flip doesn't animate")
+	}
+	alice.press("q", "w", "a")
+	if !boardEmpty(g.gs.m.board) {
+		t.Fatalf("moves were made during the flip:\n%s", alice.view())
+	}
+	// skip to the last frame
+	alice.m.flip = 1
+	alice.update(tickMsg(time.Now()))
+	g.settle()
+	if alice.m.flip != 0 {
+		t.Fatalf("the coin flip didn't end")
+	}
+	want := []move{{x: 0, y: 0}, {x: 0, y: 1}, {x: 1, y: 0}}
+	moves := g.gs.m.record.moves
+	if len(moves) != len(want) {
+		t.Fatalf("%d moves made after the flip, want the %d buffered:\n%s", len(moves), len(want), alice.view())
+	}
+	for i, mv := range moves {
+		if mv.x != want[i].x || mv.y != want[i].y {
+			t.Errorf("move %d is (%d,%d), want (%d,%d)", i+1, mv.x, mv.y, want[i].x, want[i].y)
+		}
+	}
+	if b := g.gs.m.board; b[0][0] != 1 || b[0][1] != -1 || b[1][0] != 1 {
+		t.Errorf("the buffered moves don't alternate the pieces:\n%s", alice.view())
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Default cast dimensions, used when the session size is unknown.
+const (
+	castWidth  = 80
+	castHeight = 24
+)
+
+// castHeader is the first line of an asciicast v2 file.
+type castHeader struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Title     string `json:"title,omitempty"`
+	// Game is the game cast, to verify the cast by, see verifyCast
+	Game *castGame `json:"game,omitempty"`
+}
+
+// exportCast writes the game recorded in m to path as an asciicast v2 file.
+// The game is replayed move by move on a fresh board and every resulting
+// View is emitted as a frame at the time the move was recorded.
+func exportCast(m model, path string) error {
+	rec := m.record
+	if len(rec.moves) == 0 {
+		return errors.New("no recorded moves to export")
+	}
+
+	// replay on a copy so the live model is left alone
+	r := m
+	r.view = 1
+	r.currentPlayer = rec.first
+	r.players[0].score = rec.scores[0]
+	r.players[1].score = rec.scores[1]
+	r.board = newBoard()
+	r.record = recording{id: rec.id, started: rec.started, first: rec.first, scores: rec.scores}
+	r.gameOver = false
+	r.winner = 0
+	r.notice = ""
+	r.chat = nil
+	r.reactions = nil
+	r.takeback = takeback{}
+	r.turnLeft = 0
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	width, height := m.me().width, m.me().height
+	if width <= 0 || height <= 0 {
+		width, height = castWidth, castHeight
+	}
+	if err := enc.Encode(castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: rec.started.Unix(),
+		Title:     m.players[0].name + " vs " + m.players[1].name,
+		Game:      newCastGame(m),
+	}); err != nil {
+		return err
+	}
+
+	frame := func(t float64) error {
+		// clear the screen and redraw the board from the top left corner
+		out := "\x1b[2J\x1b[H" + strings.ReplaceAll(r.View(), "\n", "\r\n")
+		return enc.Encode([]interface{}{t, "o", out})
+	}
+	if err := frame(0); err != nil {
+		return err
+	}
+	for _, mv := range rec.moves {
+		updateCell(&r, mv.x, mv.y)
+		if err := frame(mv.at.Sub(rec.started).Seconds()); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// Say sends the chat message of the session m to every session and, with
+// -chatdir, appends it to the chat log of the current game.
+func (gs *gameState) Say(m model, msg chatMsg) tea.Cmd {
+	if cfg.ChatDir != "" {
+		id := gs.gameID()
+		if err := logChat(id, m.identity, msg); err != nil {
+			log.Error("Could not log chat", "game", id, "error", err)
+		}
+	}
+	return gs.broadcast(msg)
+}
+
+// gameID returns the ID of the current game.
+func (gs *gameState) gameID() string {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.m.record.id
+}
+
+// logChat appends msg, sent by the player known as identity, to the chat log
+// of the game id, <id>.log in -chatdir.
+func logChat(id, identity string, msg chatMsg) error {
+	f, err := os.OpenFile(filepath.Join(cfg.ChatDir, id+".log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "%s %s (%s): %s\n", time.Now().UTC().Format(time.RFC3339), msg.from, identity, msg.text); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
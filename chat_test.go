@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestChatTypingLeavesBoard(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	alice.press("t")
+	// s, a and d are cells and the keys of a view
+	alice.typeText("sad")
+	if !boardEmpty(g.gs.m.board) || alice.m.view != 1 {
+		t.Fatalf("typing in chat played or switched views:\n%s", alice.view())
+	}
+	if got := alice.m.chatInput.Value(); got != "sad" {
+		t.Fatalf("chat input holds %q, want sad", got)
+	}
+	alice.press("enter")
+	if n := len(bob.m.chat); n == 0 || bob.m.chat[n-1].text != "sad" {
+		t.Errorf("bob didn't get the chat line, has %v", bob.m.chat)
+	}
+	if !boardEmpty(g.gs.m.board) {
+		t.Errorf("sending the chat line played:\n%s", alice.view())
+	}
+}
+
+func TestEscResetsInputInFocus(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	g.connect("bob")
+	alice.press("t")
+	alice.typeText("hel")
+	alice.press("esc")
+	if got := alice.m.chatInput.Value(); got != "" {
+		t.Errorf("chat input holds %q after esc, want it empty", got)
+	}
+	alice.press("2", "0")
+	if alice.m.focus != focusName {
+		t.Fatalf("alice isn't in the name entry, focus %d", alice.m.focus)
+	}
+	alice.typeText("xyz")
+	alice.press("esc")
+	if got := alice.m.textInput.Value(); got != "alice" {
+		t.Errorf("name entry holds %q after esc, want the name in use", got)
+	}
+	if got := g.gs.m.players[0].name; got != "alice" {
+		t.Errorf("alice plays as %q after esc, want her name kept", got)
+	}
+}
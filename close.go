@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/bubbletea"
+)
+
+// closeReason tells why a session was closed, in the log and, looked up as
+// the UI string "bye" + reason, in the farewell.
+type closeReason string
+
+const (
+	reasonFull       closeReason = "full"       // both seats taken, no spectators
+	reasonSpectators closeReason = "spectators" // see -maxspectators
+	reasonQuiet      closeReason = "quiet"      // see -quiethours
+	reasonIdle       closeReason = "idle"       // see -spectatoridle
+	reasonQuit       closeReason = "quit"       // ctrl+c
+	reasonError      closeReason = "error"      // the session panicked
+	reasonBehind     closeReason = "behind"     // the session stopped reading, see mailbox
+)
+
+// Quit unregisters the session id, which is about to quit its program, so
+// its seat is free and the others told right away rather than once the
+// connection is torn down. The reason is kept for the farewell once the
+// program is done.
+func (gs *gameState) Quit(id string, reason closeReason) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if _, ok := gs.sessions[id]; !ok {
+		return
+	}
+	gs.quits[id] = reason
+	gs.unregisterLocked(id)
+}
+
+// quit ends the session's program for reason.
+func (m model) quit(reason closeReason) tea.Cmd {
+	m.gs.Quit(m.session, reason)
+	return tea.Quit
+}
+
+// closeSession says goodbye to s explaining reason, logs it, and closes s.
+// args fill in the farewell of reasons that take them.
+func closeSession(s ssh.Session, reason closeReason, args ...interface{}) {
+	m := model{lang: langFromEnv(sshEnv(s, "LANG"), cfg.Lang)}
+	style := bubbletea.MakeRenderer(s).NewStyle().Foreground(lipgloss.Color("8"))
+	wish.Println(s, style.Render(fmt.Sprintf(m.tr("bye"+string(reason)), args...)))
+	if connSampled(s) {
+		log.Info("Closed session", append(connFields(s), "reason", reason)...)
+	}
+	state.UnregisterSession(sessionID(s))
+	s.Close()
+}
+
+// closeMiddleware closes the sessions whose program quit, with the reason
+// they quit for. Sessions that were closed already or dropped their
+// connection are left alone.
+func closeMiddleware(next ssh.Handler) ssh.Handler {
+	return func(s ssh.Session) {
+		if reason, ok := state.QuitReason(sessionID(s)); ok && s.Context().Err() == nil {
+			restoreTitle(s)
+			closeSession(s, reason)
+		}
+		next(s)
+	}
+}
+
+// QuitReason returns the reason the session id quit for, quit unless said
+// otherwise, and whether the session quit or is still registered at all.
+func (gs *gameState) QuitReason(id string) (closeReason, bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if reason, ok := gs.quits[id]; ok {
+		delete(gs.quits, id)
+		return reason, true
+	}
+	_, ok := gs.sessions[id]
+	return reasonQuit, ok
+}
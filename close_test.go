@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestQuitFreesSeat(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		keys []string // leading alice from the board to the view
+		view int
+	}{
+		{"board", nil, 1},
+		{"menu", []string{"2"}, 2},
+		{"name entry", []string{"2", "0"}, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t)
+			alice := g.connect("alice")
+			bob := g.connect("bob")
+			alice.press(tt.keys...)
+			if alice.m.view != tt.view {
+				t.Fatalf("alice is at view %d, want %d", alice.m.view, tt.view)
+			}
+			// the quit itself frees the seat, before the program ends and
+			// the connection goes
+			next, _ := alice.m.Update(keyMsg("ctrl+c"))
+			alice.m = next.(model)
+			if g.gs.seats[0] != "" {
+				t.Fatalf("seat 1 is still held by %q after alice quit", g.gs.seats[0])
+			}
+			if reason, ok := g.gs.QuitReason(alice.id); !ok || reason != reasonQuit {
+				t.Errorf("alice's session quit for %q %v, want %q", reason, ok, reasonQuit)
+			}
+			alice.quit = true
+			g.settle()
+			if !bob.m.vacant[0] {
+				t.Errorf("bob wasn't told alice left")
+			}
+			carol := g.connect("carol")
+			if carol.m.spectator || carol.m.self != 0 {
+				t.Errorf("carol didn't get alice's seat")
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/charmbracelet/log"
+)
+
+// competitive are the settings -competitive implies, by flag name, for
+// ranked play: no takebacks or hints, one cell per turn in strict
+// alternation, with timeouts losing the game rather than skipping a turn,
+// a clock on every turn and on the whole game, and the results kept in a
+// scores file. The user's own settings win over them, see unset.
+var competitive = [][2]string{
+	{"strictturns", "true"},
+	{"takebacks", "false"},
+	{"hints", "0"},
+	{"doublemove", "off"},
+	{"swaprule", "false"},
+	{"turntimeout", "30s"},
+	{"turnexpiry", "forfeit"},
+	{"gameclock", "3m"},
+	{"scores", "scores.json"},
+}
+
+// setFlags sets the flags named in settings to their values, in order.
+func setFlags(settings [][2]string) error {
+	for _, st := range settings {
+		if err := flag.Set(st[0], st[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unset returns the settings whose flags aren't given.
+func unset(settings [][2]string, given map[string]bool) [][2]string {
+	var left [][2]string
+	for _, st := range settings {
+		if !given[st[0]] {
+			left = append(left, st)
+		}
+	}
+	return left
+}
+
+// logCompetitive logs the effective values of the settings -competitive
+// implies, once the user's own overrode them.
+func logCompetitive() {
+	kv := make([]interface{}, 0, 2*len(competitive))
+	for _, st := range competitive {
+		kv = append(kv, st[0], flag.Lookup(st[0]).Value.String())
+	}
+	log.Info("Competitive preset", kv...)
+}
@@ -0,0 +1,674 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Config holds all server options. They are read from an optional JSON file
+// given with -config; flags set on the command line override the file.
+type Config struct {
+	Host      string   `json:"host"` // "0.0.0.0" to accept remote players
+	Port      string   `json:"port"`
+	Socket    string   `json:"socket"` // Unix socket listened on instead, if set
+	CastDir   string   `json:"castDir"`
+	AutoReset duration `json:"autoReset"`
+	// RandomStart draws the starting player of every game with a coin flip.
+	RandomStart bool `json:"randomStart"`
+	// Rotation picks who starts the next game of a series: the "winner",
+	// the "loser", "alternate" starts or a "random" one, like RandomStart.
+	// Unset, the player whose turn is next at the end of a game starts.
+	Rotation string `json:"rotation"`
+	// ReadyCheck holds the first move of every game until both players
+	// pressed r.
+	ReadyCheck bool `json:"readyCheck"`
+	// TurnTimeout limits each turn. When it expires the turn is skipped, or
+	// with TurnExpiry "forfeit" the game is lost.
+	TurnTimeout duration `json:"turnTimeout"`
+	TurnExpiry  string   `json:"turnExpiry"`
+	// GameClock is the time each player has for all their moves of a
+	// game, lost when it runs out. 0 for no limit.
+	GameClock duration `json:"gameClock"`
+	// StrictTurns only takes a move from the player on turn. Without it
+	// either player's press plays the piece on turn, as on a shared board.
+	StrictTurns bool `json:"strictTurns"`
+	// Bot takes the second seat with a computer player: "random" or
+	// "mirror", which reflects the last move through the center.
+	Bot string `json:"bot"`
+	// BotDelay is how long the bot appears to think about its move, 0 to
+	// play it on the next tick.
+	BotDelay duration `json:"botDelay"`
+	// Ladder has players climb from an easy to a hard bot, one win at a
+	// time, instead of playing the single Bot.
+	Ladder bool `json:"ladder"`
+	// SwapRule lets the second player take over the first move of a game
+	// instead of answering it, the pie rule.
+	SwapRule bool `json:"swapRule"`
+	// DoubleMove has the players place two cells per turn: "on", or
+	// "notfirst" for a single cell on the first move of a game. "off"
+	// places one.
+	DoubleMove string `json:"doubleMove"`
+	// Spectators lets connections beyond the two players watch the game
+	// instead of closing them.
+	Spectators bool `json:"spectators"`
+	// SpectatorIdle disconnects spectators that didn't press a key for this
+	// long, 0 never does.
+	SpectatorIdle duration `json:"spectatorIdle"`
+	// Grace keeps the seat of a player whose connection drops during a
+	// game for this long, for them to reconnect to before they forfeit.
+	// 0 frees it at once.
+	Grace duration `json:"grace"`
+	// MaxGame ends a game still on this long after its first move as a
+	// draw and frees the seats, against games stuck by a bug. 0 lets games
+	// run for any time.
+	MaxGame duration `json:"maxGame"`
+	// MaxSpectators caps the spectators watching at once, 0 doesn't.
+	MaxSpectators int  `json:"maxSpectators"`
+	Animations    bool `json:"animations"`
+	// Logo is a file with ASCII art replacing the built-in menu logo.
+	Logo string `json:"logo"`
+	// Greeting is a file with a banner written to every session before the
+	// game starts, until a key is pressed or GreetingTime passes.
+	Greeting     string   `json:"greeting"`
+	GreetingTime duration `json:"greetingTime"`
+	// EmptyFill gives empty cells a background: "solid", "checker" or
+	// "none" for minimal terminals.
+	EmptyFill string `json:"emptyFill"`
+	ShowLogo  bool   `json:"showLogo"`
+	// Theme decorates the lines struck through won games: "classic" in
+	// ASCII, "bold" or red "strike".
+	Theme string `json:"theme"`
+	// GridStyle spaces out the cells of the grid: "compact", "comfortable"
+	// with a space either side of them, or "spacious" with blank lines
+	// between the rows too.
+	GridStyle string `json:"gridStyle"`
+	// Lang is the UI language of sessions that don't send a known LANG.
+	Lang string `json:"lang"`
+	// Opening restricts the first move of a game: "nocenter" or "corner".
+	Opening string `json:"opening"`
+	// Prefs is a JSON file keeping the settings of returning players.
+	Prefs string `json:"prefs"`
+	// Scores is where the store keeps the records of the players, for
+	// ScoreStore backends keeping them at all.
+	Scores string `json:"scores"`
+	// ScoreStore is the backend of the records: "memory", lost when the
+	// server stops, "json", the Scores file, or "sqlite", a SQLite
+	// database at Scores. It defaults to json if Scores is set.
+	ScoreStore string `json:"scoreStore"`
+	// GeoDB is a CSV file of networks and regions to tag connections with.
+	GeoDB string `json:"geoDB"`
+	// Puzzles is a JSON file of positions for the puzzle of the day.
+	Puzzles string `json:"puzzles"`
+	MaxName int    `json:"maxName"`
+	// Filter masks the words of WordList in chat and rejects names with them.
+	Filter   bool   `json:"filter"`
+	WordList string `json:"wordList"`
+	// WinRule names the set of lines that win a game, CustomLines replaces
+	// it with lines of cells given as [row, column] in the config file.
+	WinRule     string `json:"winRule"`
+	CustomLines []line `json:"customLines"`
+	// LogFormat is "text" for people or "json" for log pipelines.
+	LogFormat string `json:"logFormat"`
+	// TickRate is the interval of the tick driving animations and
+	// countdowns. Every tick may redraw the view of each session, so a
+	// shorter interval animates more smoothly at the cost of more SSH
+	// traffic; views that don't change between ticks send nothing.
+	TickRate duration `json:"tickRate"`
+	// Coalesce combines the redraws of changes made within one tick.
+	Coalesce bool `json:"coalesce"`
+	// RedrawFilter skips the redraws of spectators off the game screen.
+	RedrawFilter bool `json:"redrawFilter"`
+	// History is how many of the last moves of a game can be taken back, 0
+	// for all of them. Moves past it stay on the board and in the
+	// recording, which keeps the whole game for casts and other exports.
+	History int `json:"history"`
+	// Takebacks lets players ask to take back moves at all.
+	Takebacks bool `json:"takebacks"`
+	// TermAllow lists the terminal types (TERM) known to draw the board,
+	// as patterns like "xterm*"; empty allows them all. Terminals
+	// matching TermDeny or missing from a non-empty TermAllow get an
+	// ASCII board instead.
+	TermAllow list `json:"termAllow"`
+	TermDeny  list `json:"termDeny"`
+	// Hints is how many hints, suggested moves, a player gets per game
+	// against the bot; -1 gives any number and 0 none. Two people only get
+	// them with PvPHints.
+	Hints    int  `json:"hints"`
+	PvPHints bool `json:"pvpHints"`
+	// ServerName brands the server, in place of the game's name on the
+	// menu, above the greeting and as the prefix of the log.
+	ServerName string `json:"serverName"`
+	// ChatDir is a directory to log the chat of every game to, as
+	// <game ID>.log, with the text as masked by Filter.
+	ChatDir string `json:"chatDir"`
+	// Preview shows the piece to play, dimmed, on the empty cell under the
+	// cursor of the arrow keys.
+	Preview bool `json:"preview"`
+	// QuietHours is a daily window, like "22:00-07:00" in QuietZone, during
+	// which no new game starts and only a game in progress lets sessions
+	// in, to finish it.
+	QuietHours string `json:"quietHours"`
+	// QuietZone is the IANA time zone of QuietHours, the server's if empty.
+	QuietZone string `json:"quietZone"`
+	// Keys is the scheme of the keys playing the cells: "qwerty" for the
+	// block from q to c, or "numpad" for the digits in reading order, which
+	// moves the menu from 2 to tab.
+	Keys string `json:"keys"`
+	// KeyLabels shows new players the key of every empty cell until they
+	// made a few moves, or chose with k.
+	KeyLabels bool `json:"keyLabels"`
+	// WhenFull is what happens to connections finding both seats taken:
+	// "close" them, let them "spectate", or "queue" them as spectators in
+	// line for the next free seat. It defaults to spectate with
+	// Spectators, and close without.
+	WhenFull string `json:"whenFull"`
+	// LobbySpectators lets every spectator into the lobby chat, not only
+	// those in line for a seat.
+	LobbySpectators bool `json:"lobbySpectators"`
+	// Title sets the terminal title to the server name and the opponent,
+	// and restores it when the session closes, on terminals supporting it.
+	Title bool `json:"title"`
+	// OpponentCursor shares the players' cursors: each sees the opponent's
+	// faint, unless they turn it off, and spectators see both. It is off
+	// by default, as it gives the moves away.
+	OpponentCursor bool `json:"opponentCursor"`
+	// BotThinking shows spectators the best move the minimax bot found so
+	// far while it thinks. Players never see it.
+	BotThinking bool `json:"botThinking"`
+	// MoveLimit ends a game without a winner after that many moves,
+	// passes included, 0 for no limit. OnLimit decides it: "draw", or
+	// "pieces" for a win of the piece holding more cells.
+	MoveLimit int    `json:"moveLimit"`
+	OnLimit   string `json:"onLimit"`
+	// TimingFlag logs the players whose move timings in a game score this
+	// suspicion or more, see suspicion, 0 for none. It never acts on them.
+	TimingFlag float64 `json:"timingFlag"`
+	// LogSample logs 1 in that many connections and timed out moves, 1
+	// for all of them. Errors and game results are always logged.
+	LogSample int `json:"logSample"`
+	// StartView is the view sessions start at: "name" to enter the name
+	// to play under, "board" or "menu".
+	StartView string `json:"startView"`
+	// Teach shows the loser of a game the move they lost it with and the
+	// one that would have held it, searching every position of the game.
+	Teach bool `json:"teach"`
+	// Competitive sets up ranked play, see competitive for the settings it
+	// implies. They win over the config file, flags given on the command
+	// line over them.
+	Competitive bool `json:"competitive"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Host:         "localhost",
+		Port:         "23234",
+		Animations:   true,
+		ShowLogo:     true,
+		EmptyFill:    "checker",
+		Theme:        "classic",
+		GridStyle:    "compact",
+		GreetingTime: duration(5 * time.Second),
+		BotDelay:     duration(500 * time.Millisecond),
+		MaxGame:      duration(2 * time.Hour),
+		TurnExpiry:   "skip",
+		DoubleMove:   "off",
+		Lang:         defaultLang,
+		MaxName:      20,
+		WinRule:      "standard",
+		TickRate:     duration(100 * time.Millisecond),
+		LogFormat:    "text",
+		Coalesce:     true,
+		RedrawFilter: true,
+		Takebacks:    true,
+		Hints:        3,
+		OnLimit:      "draw",
+		ServerName:   defaultServerName,
+		Preview:      true,
+		Keys:         "qwerty",
+		LogSample:    1,
+		StartView:    "name",
+	}
+}
+
+// cfg is the effective configuration, set up once in main.
+var cfg = defaultConfig()
+
+var configPath = flag.String("config", "", "path to a JSON config file")
+
+var verifyPath = flag.String("verify", "", "check the cast exported to this path against its game's hash, instead of serving")
+
+func init() {
+	flag.StringVar(&cfg.Host, "host", cfg.Host, "address to listen on")
+	flag.StringVar(&cfg.Port, "port", cfg.Port, "port to listen on")
+	flag.StringVar(&cfg.Socket, "socket", cfg.Socket, "path of a Unix socket to listen on instead of -host and -port")
+	flag.StringVar(&cfg.CastDir, "castdir", cfg.CastDir, "directory to export finished games to as asciinema casts")
+	flag.StringVar(&cfg.ChatDir, "chatdir", cfg.ChatDir, "directory to log the chat of every game to")
+	flag.Var(&cfg.AutoReset, "autoreset", "reset the board this long after a game ends (0 waits for esc)")
+	flag.BoolVar(&cfg.RandomStart, "randomstart", cfg.RandomStart, "start every game with a random player")
+	flag.StringVar(&cfg.Rotation, "rotation", cfg.Rotation, `who starts the next game of a series: "winner", "loser", "alternate" or "random"`)
+	flag.BoolVar(&cfg.ReadyCheck, "readycheck", cfg.ReadyCheck, "wait for both players to press r before the first move")
+	flag.Var(&cfg.TurnTimeout, "turntimeout", "time each player has for a move (0 for no limit)")
+	flag.Var(&cfg.GameClock, "gameclock", "time each player has for all their moves of a game (0 for no limit)")
+	flag.BoolVar(&cfg.StrictTurns, "strictturns", cfg.StrictTurns, "only take a move from the player on turn")
+	flag.StringVar(&cfg.TurnExpiry, "turnexpiry", cfg.TurnExpiry, `what a turn timeout does: "skip" the turn or "forfeit" the game`)
+	flag.StringVar(&cfg.Bot, "bot", cfg.Bot, `play the second seat with a bot: "random", "mirror", "greedy" or "minimax"`)
+	flag.Var(&cfg.BotDelay, "botdelay", "how long the bot appears to think about its move (0 to play at once)")
+	flag.BoolVar(&cfg.Ladder, "ladder", cfg.Ladder, "play bots of rising strength, one win to climb each rung")
+	flag.StringVar(&cfg.DoubleMove, "doublemove", cfg.DoubleMove, `cells placed per turn: "off" for one, "on" for two, "notfirst" for two, but one on the first move of a game`)
+	flag.BoolVar(&cfg.SwapRule, "swaprule", cfg.SwapRule, "let the second player swap sides after the first move")
+	flag.BoolVar(&cfg.Spectators, "spectators", cfg.Spectators, "let connections beyond the two players watch")
+	flag.IntVar(&cfg.MaxSpectators, "maxspectators", cfg.MaxSpectators, "most spectators watching at once (0 for no limit)")
+	flag.Var(&cfg.MaxGame, "maxgame", "end a game still on this long after its first move as a draw and free the seats (0 never does)")
+	flag.Var(&cfg.Grace, "grace", "keep the seat of a player dropping out of a game for this long (0 frees it at once)")
+	flag.Var(&cfg.SpectatorIdle, "spectatoridle", "disconnect spectators idle for this long (0 never does)")
+	flag.BoolVar(&cfg.Animations, "animations", cfg.Animations, "animate the coin flip")
+	flag.StringVar(&cfg.Logo, "logo", cfg.Logo, "file with ASCII art to show on the menu")
+	flag.StringVar(&cfg.Greeting, "greeting", cfg.Greeting, "file with a banner to greet every session with")
+	flag.Var(&cfg.GreetingTime, "greetingtime", "show the greeting this long unless a key is pressed")
+	flag.StringVar(&cfg.GridStyle, "gridstyle", cfg.GridStyle, `spacing of the cells of the grid: "compact", "comfortable" or "spacious"`)
+	flag.StringVar(&cfg.Theme, "theme", cfg.Theme, `decoration of the lines through won games: "classic", "bold" or "strike"`)
+	flag.StringVar(&cfg.EmptyFill, "emptyfill", cfg.EmptyFill, `background of empty cells: "solid", "checker" or "none" for minimal terminals`)
+	flag.BoolVar(&cfg.ShowLogo, "showlogo", cfg.ShowLogo, "show the logo on the menu, disable for minimal terminals")
+	flag.StringVar(&cfg.Lang, "lang", cfg.Lang, "UI language for sessions without a known LANG")
+	flag.StringVar(&cfg.Opening, "opening", cfg.Opening, `restrict the first move: "nocenter" or "corner"`)
+	flag.StringVar(&cfg.Prefs, "prefs", cfg.Prefs, "JSON file to keep the settings of returning players in")
+	flag.StringVar(&cfg.Scores, "scores", cfg.Scores, "file to keep the records of the players in")
+	flag.StringVar(&cfg.ScoreStore, "scorestore", cfg.ScoreStore, `backend of the records: "memory", "json" or "sqlite" (default json with -scores)`)
+	flag.StringVar(&cfg.GeoDB, "geodb", cfg.GeoDB, "CSV file of networks and regions to tag connections with in the logs")
+	flag.StringVar(&cfg.Puzzles, "puzzles", cfg.Puzzles, "JSON file with puzzles for the puzzle of the day")
+	flag.IntVar(&cfg.MaxName, "maxname", cfg.MaxName, "maximum length of player names")
+	flag.BoolVar(&cfg.Filter, "filter", cfg.Filter, "filter the words of -wordlist from names and chat")
+	flag.StringVar(&cfg.WordList, "wordlist", cfg.WordList, "file with words to filter, one per line")
+	flag.StringVar(&cfg.LogFormat, "logformat", cfg.LogFormat, `format of the log: "text" or "json"`)
+	flag.BoolVar(&cfg.Coalesce, "coalesce", cfg.Coalesce, "combine the redraws of changes made within one tick")
+	flag.BoolVar(&cfg.RedrawFilter, "redrawfilter", cfg.RedrawFilter, "skip the redraws of spectators off the game screen until they return to it")
+	flag.IntVar(&cfg.History, "history", cfg.History, "how many of the last moves can be taken back (0 for all)")
+	flag.BoolVar(&cfg.Takebacks, "takebacks", cfg.Takebacks, "let players ask to take back moves")
+	flag.Var(&cfg.TermAllow, "termallow", "comma-separated terminal types to draw the board for, like xterm* (empty allows all)")
+	flag.Var(&cfg.TermDeny, "termdeny", "comma-separated terminal types to draw an ASCII board for")
+	flag.IntVar(&cfg.Hints, "hints", cfg.Hints, "hints per player and game against the bot (-1 for any number, 0 for none)")
+	flag.BoolVar(&cfg.PvPHints, "pvphints", cfg.PvPHints, "give hints in games between two people too")
+	flag.StringVar(&cfg.ServerName, "servername", cfg.ServerName, "name of the server shown on the menu, the greeting and in the log")
+	flag.BoolVar(&cfg.Preview, "preview", cfg.Preview, "preview the piece to play under the cursor")
+	flag.StringVar(&cfg.QuietHours, "quiethours", cfg.QuietHours, "daily window like 22:00-07:00 when no new games start")
+	flag.StringVar(&cfg.QuietZone, "quietzone", cfg.QuietZone, "time zone of -quiethours, like Europe/Berlin (default the server's)")
+	flag.StringVar(&cfg.Keys, "keys", cfg.Keys, `keys playing the cells: "qwerty" or "numpad"`)
+	flag.StringVar(&cfg.StartView, "startview", cfg.StartView, `view sessions start at: "name", "board" or "menu"`)
+	flag.BoolVar(&cfg.Teach, "teach", cfg.Teach, "show the loser of a game the move that lost it and a better one")
+	flag.BoolVar(&cfg.Competitive, "competitive", cfg.Competitive, "set up ranked play: no takebacks, strict turns, clocks and recorded results")
+	flag.IntVar(&cfg.LogSample, "logsample", cfg.LogSample, "log 1 in that many connections and timed out moves (errors and game results always)")
+	flag.Float64Var(&cfg.TimingFlag, "timingflag", cfg.TimingFlag, "log players whose move timings score this suspicion of being a bot or more, up to 1 (0 for none)")
+	flag.IntVar(&cfg.MoveLimit, "movelimit", cfg.MoveLimit, "moves after which a game without a winner ends (0 for no limit)")
+	flag.StringVar(&cfg.OnLimit, "onlimit", cfg.OnLimit, `how a game reaching -movelimit ends: "draw", or "pieces" for a win of the piece holding more cells`)
+	flag.BoolVar(&cfg.BotThinking, "botthinking", cfg.BotThinking, "show spectators the bot's best move so far while it thinks")
+	flag.BoolVar(&cfg.OpponentCursor, "opponentcursor", cfg.OpponentCursor, "show the players where their opponent's cursor is")
+	flag.BoolVar(&cfg.Title, "title", cfg.Title, "set the terminal title to the server name and the opponent")
+	flag.BoolVar(&cfg.KeyLabels, "keylabels", cfg.KeyLabels, "label the empty cells with their keys for new players")
+	flag.BoolVar(&cfg.LobbySpectators, "lobbyspectators", cfg.LobbySpectators, "let every spectator into the lobby chat, not only those in line for a seat")
+	flag.StringVar(&cfg.WhenFull, "whenfull", cfg.WhenFull, `what connections finding both seats taken do: "close", "spectate" or "queue" (default spectate with -spectators)`)
+	flag.Var(&cfg.TickRate, "tickrate", "interval of the tick driving animations and countdowns, shorter is smoother but sends more")
+	flag.StringVar(&cfg.WinRule, "winrule", cfg.WinRule, `lines that win a game: "standard", "nodiagonals" or "diagonals"`)
+}
+
+// setupConfig parses the flags and merges them over the config file, if any.
+func setupConfig() error {
+	flag.Parse()
+	// remember what was given on the command line, it wins over the file
+	// and the preset
+	var set [][2]string
+	// given are the flags the user set, on the command line or in the
+	// file, which the preset leaves alone
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set = append(set, [2]string{f.Name, f.Value.String()})
+		given[f.Name] = true
+	})
+	if *configPath != "" {
+		c, keys, err := loadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+		cfg = c
+		if err := setFlags(set); err != nil {
+			return err
+		}
+		// the flags of the preset are named after their keys, lowercased
+		for _, k := range keys {
+			given[strings.ToLower(k)] = true
+		}
+	}
+	if cfg.Competitive {
+		if err := setFlags(unset(competitive, given)); err != nil {
+			return err
+		}
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	if cfg.LogFormat == "json" {
+		log.SetFormatter(log.JSONFormatter)
+	}
+	log.SetPrefix(cfg.ServerName)
+	if cfg.Competitive {
+		logCompetitive()
+	}
+	if cfg.QuietHours != "" {
+		quietHours, _ = parseQuietHours(cfg.QuietHours)
+	}
+	if cfg.QuietZone != "" {
+		quietZone, _ = time.LoadLocation(cfg.QuietZone)
+	}
+	if cfg.WhenFull == "" {
+		cfg.WhenFull = "close"
+		if cfg.Spectators {
+			cfg.WhenFull = "spectate"
+		}
+	}
+	// a random rotation is the coin flip of every game
+	if cfg.Rotation == "random" {
+		cfg.RandomStart = true
+	}
+	// the ladder seats a bot, its strength is picked per player
+	if cfg.Ladder {
+		cfg.Bot = ladder[0]
+	}
+	if cfg.Logo != "" {
+		if err := loadLogo(cfg.Logo); err != nil {
+			return fmt.Errorf("logo: %w", err)
+		}
+	}
+	if cfg.Greeting != "" {
+		if err := loadGreeting(cfg.Greeting); err != nil {
+			return fmt.Errorf("greeting: %w", err)
+		}
+	}
+	winLines = winRules[cfg.WinRule]
+	if len(cfg.CustomLines) > 0 {
+		winLines = cfg.CustomLines
+	}
+	if cfg.Filter {
+		if err := loadWordList(cfg.WordList); err != nil {
+			return fmt.Errorf("word list: %w", err)
+		}
+	}
+	if cfg.Prefs != "" {
+		ps, err := loadPrefs(cfg.Prefs)
+		if err != nil {
+			return fmt.Errorf("prefs: %w", err)
+		}
+		savedPrefs = ps
+	}
+	if cfg.ScoreStore == "" {
+		cfg.ScoreStore = "memory"
+		if cfg.Scores != "" {
+			cfg.ScoreStore = "json"
+		}
+	}
+	st, err := stores[cfg.ScoreStore](cfg.Scores)
+	if err != nil {
+		return fmt.Errorf("scores: %w", err)
+	}
+	scores = st
+	if cfg.GeoDB != "" {
+		setupGeo(cfg.GeoDB)
+	}
+	if cfg.Puzzles != "" {
+		ps, err := loadPuzzles(cfg.Puzzles)
+		if err != nil {
+			return fmt.Errorf("puzzles: %w", err)
+		}
+		puzzles = ps
+	}
+	return nil
+}
+
+// loadConfig reads the config file at path on top of the defaults, and
+// returns the keys it sets.
+func loadConfig(path string) (Config, []string, error) {
+	c := defaultConfig()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return c, nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	var set map[string]json.RawMessage
+	if err := json.Unmarshal(b, &set); err != nil {
+		return c, nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	return c, keys, nil
+}
+
+func (c Config) validate() error {
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("port %q is not a valid port number", c.Port)
+	}
+	if c.AutoReset < 0 {
+		return errors.New("autoReset must not be negative")
+	}
+	if c.TickRate < duration(10*time.Millisecond) || c.TickRate > duration(time.Second) {
+		return errors.New("tickRate must be between 10ms and 1s")
+	}
+	if c.MaxSpectators < 0 {
+		return errors.New("maxSpectators must not be negative")
+	}
+	if c.SpectatorIdle < 0 {
+		return errors.New("spectatorIdle must not be negative")
+	}
+	if c.Grace < 0 {
+		return errors.New("grace must not be negative")
+	}
+	if c.MaxGame < 0 {
+		return errors.New("maxGame must not be negative")
+	}
+	for _, pattern := range append(append(list{}, c.TermAllow...), c.TermDeny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("terminal pattern %q: %w", pattern, err)
+		}
+	}
+	if c.QuietHours != "" {
+		if _, err := parseQuietHours(c.QuietHours); err != nil {
+			return err
+		}
+	}
+	if c.QuietZone != "" {
+		if _, err := time.LoadLocation(c.QuietZone); err != nil {
+			return fmt.Errorf("quietZone: %w", err)
+		}
+	}
+	if strings.TrimSpace(c.ServerName) == "" {
+		return errors.New("serverName must not be empty")
+	}
+	if c.TimingFlag < 0 || c.TimingFlag > 1 {
+		return fmt.Errorf("timingFlag %v is not between 0 and 1", c.TimingFlag)
+	}
+	if _, ok := views[c.StartView]; !ok {
+		return fmt.Errorf("startView %q is not one of name, board, menu", c.StartView)
+	}
+	if c.LogSample < 1 {
+		return fmt.Errorf("logSample %d is not 1 or more", c.LogSample)
+	}
+	if c.MoveLimit < 0 {
+		return fmt.Errorf("moveLimit %d is negative", c.MoveLimit)
+	}
+	if c.OnLimit != "draw" && c.OnLimit != "pieces" {
+		return fmt.Errorf("onLimit %q is not one of draw, pieces", c.OnLimit)
+	}
+	if c.Hints < -1 {
+		return errors.New("hints must be -1 or more")
+	}
+	if c.History < 0 {
+		return errors.New("history must not be negative")
+	}
+	if c.TurnTimeout < 0 {
+		return errors.New("turnTimeout must not be negative")
+	}
+	if c.GameClock < 0 {
+		return errors.New("gameClock must not be negative")
+	}
+	if _, ok := strategies[c.Bot]; c.Bot != "" && !ok {
+		return fmt.Errorf("bot %q is not one of random, mirror, greedy, minimax", c.Bot)
+	}
+	if c.BotDelay < 0 {
+		return errors.New("botDelay must not be negative")
+	}
+	if c.Ladder && c.Bot != "" {
+		return errors.New("ladder picks the bots itself, leave bot unset")
+	}
+	if _, ok := stores[c.ScoreStore]; c.ScoreStore != "" && !ok {
+		return fmt.Errorf("scoreStore %q is not one of memory, json, sqlite", c.ScoreStore)
+	}
+	if (c.ScoreStore == "json" || c.ScoreStore == "sqlite") && c.Scores == "" {
+		return fmt.Errorf("scoreStore %s needs scores, the file to keep them in", c.ScoreStore)
+	}
+	switch c.Rotation {
+	case "", "winner", "loser", "alternate", "random":
+	default:
+		return fmt.Errorf("rotation %q is not one of winner, loser, alternate, random", c.Rotation)
+	}
+	switch c.WhenFull {
+	case "", "close":
+	case "spectate", "queue":
+		if !c.Spectators {
+			return fmt.Errorf("whenFull %q needs spectators", c.WhenFull)
+		}
+	default:
+		return fmt.Errorf("whenFull %q is not one of close, spectate, queue", c.WhenFull)
+	}
+	switch c.Keys {
+	case "qwerty", "numpad":
+	default:
+		return fmt.Errorf("keys %q is not one of qwerty, numpad", c.Keys)
+	}
+	switch c.DoubleMove {
+	case "off", "on", "notfirst":
+	default:
+		return fmt.Errorf("doubleMove %q is not one of off, on, notfirst", c.DoubleMove)
+	}
+	switch c.TurnExpiry {
+	case "skip", "forfeit":
+	default:
+		return fmt.Errorf("turnExpiry %q is not one of skip, forfeit", c.TurnExpiry)
+	}
+	if c.GreetingTime <= 0 {
+		return errors.New("greetingTime must be positive")
+	}
+	if c.MaxName < 1 {
+		return errors.New("maxName must be at least 1")
+	}
+	if c.Filter && c.WordList == "" {
+		return errors.New("filter needs a wordList")
+	}
+	switch c.LogFormat {
+	case "text", "json":
+	default:
+		return fmt.Errorf("logFormat %q is not one of text, json", c.LogFormat)
+	}
+	if _, ok := gridStyles[c.GridStyle]; !ok {
+		return fmt.Errorf("gridStyle %q is not one of compact, comfortable, spacious", c.GridStyle)
+	}
+	if _, ok := themes[c.Theme]; !ok {
+		return fmt.Errorf("theme %q is not one of classic, bold, strike", c.Theme)
+	}
+	switch c.EmptyFill {
+	case "none", "solid", "checker":
+	default:
+		return fmt.Errorf("emptyFill %q is not one of none, solid, checker", c.EmptyFill)
+	}
+	switch c.Opening {
+	case "", "nocenter", "corner":
+	default:
+		return fmt.Errorf("opening %q is not one of nocenter, corner", c.Opening)
+	}
+	if _, ok := winRules[c.WinRule]; !ok {
+		return fmt.Errorf("winRule %q is not one of standard, nodiagonals, diagonals", c.WinRule)
+	}
+	for i, l := range c.CustomLines {
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("customLines %d: %w", i+1, err)
+		}
+	}
+	if _, ok := catalogs[c.Lang]; !ok {
+		return fmt.Errorf("lang %q has no translation", c.Lang)
+	}
+	if c.CastDir != "" {
+		if fi, err := os.Stat(c.CastDir); err != nil || !fi.IsDir() {
+			return fmt.Errorf("castDir %q is not a directory", c.CastDir)
+		}
+	}
+	if c.ChatDir != "" {
+		if fi, err := os.Stat(c.ChatDir); err != nil || !fi.IsDir() {
+			return fmt.Errorf("chatDir %q is not a directory", c.ChatDir)
+		}
+	}
+	return nil
+}
+
+func (c Config) String() string {
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// duration is a time.Duration written as a string like "5s", both in the
+// config file and on the command line.
+type duration time.Duration
+
+func (d duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %w", err)
+	}
+	return d.Set(s)
+}
+
+// list is a list of strings, written as an array in the config file and
+// comma separated on the command line.
+type list []string
+
+func (l list) String() string {
+	return strings.Join(l, ",")
+}
+
+func (l *list) Set(s string) error {
+	*l = nil
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cursor is the cell picked with the arrow keys, played with enter or space.
+type cursor struct {
+	x, y  int
+	shown bool // the arrow keys were used; until then no cursor is drawn
+}
+
+// cursorThrottle is how often at most a player's cursor is shared with
+// the other sessions, see -opponentcursor.
+const cursorThrottle = 100 * time.Millisecond
+
+// cursorMsg tells the sessions where the cursor of the player in seat is.
+type cursorMsg struct {
+	seat   int
+	cursor cursor
+}
+
+// moveCursor moves the cursor by dx rows and dy columns, wrapping around
+// the board. The first arrow key only brings up the cursor, on the center.
+func (m *model) moveCursor(dx, dy int) tea.Cmd {
+	if !m.cursor.shown {
+		m.cursor = cursor{x: 1, y: 1, shown: true}
+	} else {
+		m.cursor.x = (m.cursor.x + dx + 3) % 3
+		m.cursor.y = (m.cursor.y + dy + 3) % 3
+	}
+	return m.shareCursor()
+}
+
+// shareCursor broadcasts the player's cursor, at most once every
+// cursorThrottle. A move in between is shared by tickCursor once the
+// throttle is over.
+func (m *model) shareCursor() tea.Cmd {
+	if !cfg.OpponentCursor || m.spectator {
+		return nil
+	}
+	if m.cursorWait > 0 {
+		m.cursorMoved = true
+		return nil
+	}
+	m.cursorWait = ticks(cursorThrottle)
+	m.cursorMoved = false
+	return m.gs.broadcast(cursorMsg{seat: m.self, cursor: m.cursor})
+}
+
+// tickCursor lets the throttle of shareCursor pass.
+func (m *model) tickCursor() tea.Cmd {
+	if m.cursorWait == 0 {
+		return nil
+	}
+	m.cursorWait--
+	if m.cursorWait == 0 && m.cursorMoved {
+		return m.shareCursor()
+	}
+	return nil
+}
+
+// ghosted reports whether the cursor of another player is on x, y and
+// shown to the session: spectators see both players', players their
+// opponent's unless they turned it off.
+func (m model) ghosted(x, y int) bool {
+	if !cfg.OpponentCursor || !m.spectator && m.hideCursors {
+		return false
+	}
+	for seat, c := range m.cursors {
+		if (m.spectator || seat != m.self) && c.shown && c.x == x && c.y == y {
+			return true
+		}
+	}
+	return false
+}
+
+// ghostCells reports whether any cursor of another player is shown to the
+// session.
+func (m model) ghostCells() bool {
+	for x := range m.board {
+		for y := range m.board[x] {
+			if m.ghosted(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cursorCell draws the cell under the cursor underlined. An empty one
+// previews the piece to play, dimmed, see -preview.
+func (m model) cursorCell() string {
+	p := m.me()
+	if m.board[m.cursor.x][m.cursor.y] != 0 || p.renderer == nil {
+		return p.text().Copy().Underline(true).Render(p.cell(m.board, m.cursor.x, m.cursor.y))
+	}
+	glyph := string(p.glyph(0))
+	if cfg.Preview && !m.gameOver && !m.spectator {
+		glyph = string(p.glyph(m.currentPlayer))
+	}
+	return p.faint().Copy().Underline(true).Render(glyph)
+}
+
+// boardGrid draws the board of the session, with the hint, the cursor,
+// the other players' cursors, faint, the bot's best move so far for
+// spectators and the key labels.
+func (m model) boardGrid() string {
+	p := m.me()
+	hinted := m.hinted()
+	if !hinted && !m.cursor.shown && !m.labels && !m.ghostCells() && !m.thought(m.thinking.x, m.thinking.y) {
+		return p.grid(m.board)
+	}
+	return p.frame(func(x, y int) string {
+		switch {
+		case hinted && x == m.hint.x && y == m.hint.y:
+			return m.hintCell()
+		case m.cursor.shown && x == m.cursor.x && y == m.cursor.y:
+			return m.cursorCell()
+		case m.ghosted(x, y) && p.renderer != nil:
+			return p.faint().Copy().Underline(true).Render(p.cell(m.board, x, y))
+		case m.thought(x, y):
+			return p.faint().Render(string(p.glyph(m.currentPlayer)))
+		case m.labels && m.board[x][y] == 0:
+			return p.faint().Render(keyLabel(p.keyScheme(), x, y))
+		}
+		return p.cell(m.board, x, y)
+	})
+}
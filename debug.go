@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// profileNames are the names of the termenv color profiles, by value.
+var profileNames = [...]string{"TrueColor", "ANSI256", "ANSI", "Ascii"}
+
+// debugView lists the connection info of the session's player.
+func (m model) debugView() string {
+	p := m.me()
+	profile := "unknown"
+	if p.renderer != nil {
+		if cp := int(p.renderer.ColorProfile()); cp >= 0 && cp < len(profileNames) {
+			profile = profileNames[cp]
+		}
+	}
+	seat := fmt.Sprint(m.self + 1)
+	if m.spectator {
+		seat = "spectator"
+	}
+	s := fmt.Sprintf("Player: %s\nTerm: %s\nWindow: %dx%d\nBackground: %s\nColors: %s",
+		seat, p.term, p.width, p.height, p.bg, profile)
+	return p.text().Border(lipgloss.NormalBorder()).Render(s)
+}
+
+// overlay draws top over the first lines of v.
+func overlay(v string, top string) string {
+	lines := strings.Split(v, "\n")
+	for i, l := range strings.Split(top, "\n") {
+		if i < len(lines) {
+			lines[i] = l
+		} else {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,28 @@
+package main
+
+// placements is the number of cells a player places per turn, for the
+// move just recorded in rec: one, or two with -doublemove, except on the
+// first move of a game with -doublemove notfirst, so that the player
+// starting doesn't get too far ahead.
+func placements(rec recording) int {
+	switch cfg.DoubleMove {
+	case "on":
+		return 2
+	case "notfirst":
+		if len(rec.moves) > 1 {
+			return 2
+		}
+	}
+	return 1
+}
+
+// place puts the piece of the player to move on the empty cell c of m,
+// passing the turn on once the player placed all of theirs.
+func place(m *model, c *int) {
+	*c = m.currentPlayer
+	m.record.placed++
+	if m.record.placed >= placements(m.record) {
+		m.currentPlayer *= -1
+		m.record.placed = 0
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+// cells are the coordinates of the cell keys in qwerty, for updateCell.
+var cells = map[string][2]int{
+	"q": {0, 0}, "w": {0, 1}, "e": {0, 2},
+	"a": {1, 0}, "s": {1, 1}, "d": {1, 2},
+	"z": {2, 0}, "x": {2, 1}, "c": {2, 2},
+	"pass": {pass, pass},
+}
+
+func TestDoubleMoveTurns(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		double string
+		moves  []string
+		// the player to move after each of the moves
+		turns []int
+	}{
+		{"off", "off", []string{"q", "w", "a"}, []int{-1, 1, -1}},
+		{"on", "on", []string{"q", "w", "a", "s", "z"}, []int{1, -1, -1, 1, 1}},
+		{"notfirst", "notfirst", []string{"q", "w", "a", "s", "d"}, []int{-1, -1, 1, 1, -1}},
+		// a pass gives up the rest of the turn, and the next starts afresh
+		{"pass mid-turn", "on", []string{"q", "pass", "w", "a", "pass"}, []int{1, -1, -1, 1, -1}},
+		{"pass on the first move", "notfirst", []string{"pass", "q", "w"}, []int{-1, -1, 1}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t)
+			cfg.DoubleMove = tt.double
+			m := g.gs.m
+			for i, k := range tt.moves {
+				c := cells[k]
+				updateCell(&m, c[0], c[1])
+				if m.currentPlayer != tt.turns[i] {
+					t.Fatalf("after %v %d is to move, want %d", tt.moves[:i+1], m.currentPlayer, tt.turns[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDoubleMoveWinOnFirstPlacement(t *testing.T) {
+	g := newTestGame(t)
+	cfg.DoubleMove = "on"
+	m := g.gs.m
+	// X: q w, O: a s, then X completes the top row with the first of two
+	for _, k := range []string{"q", "w", "a", "s"} {
+		c := cells[k]
+		updateCell(&m, c[0], c[1])
+	}
+	if won := updateCell(&m, 0, 2); !won {
+		t.Fatal("the first placement of the turn didn't win")
+	}
+	if !m.gameOver || m.winner != 1 || m.players[0].score != 1 {
+		t.Errorf("gameOver %v winner %d score %d, want a win of X", m.gameOver, m.winner, m.players[0].score)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// bannedWords holds the lower-cased words masked by filterText.
+var bannedWords = map[string]bool{}
+
+// loadWordList reads the words to filter from path, one per line. Empty
+// lines and lines starting with # are skipped.
+func loadWordList(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	words := map[string]bool{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		w := strings.TrimSpace(sc.Text())
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		words[strings.ToLower(w)] = true
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	bannedWords = words
+	return nil
+}
+
+// filterText masks every banned word in s with asterisks, leaving the rest
+// of s as it was. It returns s unchanged while the filter is disabled.
+func filterText(s string) string {
+	if !cfg.Filter || len(bannedWords) == 0 {
+		return s
+	}
+	runes := []rune(s)
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for i := 0; i < len(runes); {
+		if !isWord(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWord(runes[j]) {
+			j++
+		}
+		if bannedWords[strings.ToLower(string(runes[i:j]))] {
+			for k := i; k < j; k++ {
+				runes[k] = '*'
+			}
+		}
+		i = j
+	}
+	return string(runes)
+}
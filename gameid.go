@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// gameCount is the number of games started since the server started.
+var gameCount uint64
+
+// newGameID returns a short ID for a new game, unique while the server runs:
+// the number of the game in base 32, like "000a".
+func newGameID() string {
+	n := atomic.AddUint64(&gameCount, 1)
+	return fmt.Sprintf("%04s", strconv.FormatUint(n, 32))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+)
+
+// geoRange tags the addresses of a network with a coarse region.
+type geoRange struct {
+	network *net.IPNet
+	region  string
+}
+
+// geoRanges are loaded once at startup from the -geodb file.
+var geoRanges []geoRange
+
+// loadGeo reads a CSV file of network and region pairs, like
+// "203.0.113.0/24,EU". Lines starting with # are skipped.
+func loadGeo(path string) ([]geoRange, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = 2
+	var ranges []geoRange
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return ranges, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		_, network, err := net.ParseCIDR(strings.TrimSpace(rec[0]))
+		if err != nil {
+			line, _ := r.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ranges = append(ranges, geoRange{network: network, region: strings.TrimSpace(rec[1])})
+	}
+}
+
+// setupGeo loads the -geodb file. Geo tagging is only a nicety for the logs,
+// so a missing or broken file disables it instead of stopping the server.
+func setupGeo(path string) {
+	ranges, err := loadGeo(path)
+	if err != nil {
+		log.Warn("Geo tagging disabled", "path", path, "error", err)
+		return
+	}
+	geoRanges = ranges
+}
+
+// connFields are the log fields describing the connection of s.
+func connFields(s ssh.Session) []interface{} {
+	pty, _, _ := s.Pty()
+	fields := []interface{}{"name", s.User(), "ip", remoteIP(s.RemoteAddr()), "term", pty.Term}
+	if r := region(s.RemoteAddr()); r != "" {
+		fields = append(fields, "region", r)
+	}
+	return fields
+}
+
+// region returns the region of the first range containing addr, or "".
+func region(addr net.Addr) string {
+	ip := remoteIP(addr)
+	if ip == nil {
+		return ""
+	}
+	for _, r := range geoRanges {
+		if r.network.Contains(ip) {
+			return r.region
+		}
+	}
+	return ""
+}
+
+// remoteIP returns the IP of a remote address, or nil if it has none.
+func remoteIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case nil:
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
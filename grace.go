@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hold keeps the seat of a player whose connection dropped during a game,
+// for their identity to reconnect to until the grace period ends, see
+// -grace.
+type hold struct {
+	identity string
+	name     string
+	until    time.Time
+}
+
+// droppedMsg tells the sessions that the player in seat dropped and has
+// until the deadline to come back before forfeiting.
+type droppedMsg struct {
+	seat  int
+	until time.Time
+}
+
+// forfeitMsg tells the sessions that the player who dropped from seat
+// didn't come back in time, forfeiting the game if it was still on.
+type forfeitMsg struct {
+	seat int
+}
+
+// heldLocked reports whether seat is kept for a dropped player. The caller
+// must hold gs.mu.
+func (gs *gameState) heldLocked(seat int) bool {
+	return gs.held[seat].identity != "" && time.Now().Before(gs.held[seat].until)
+}
+
+// Drop unregisters the session id whose connection is gone. A seated
+// player dropping out of a game in progress keeps their seat for -grace.
+func (gs *gameState) Drop(id string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	playing := len(gs.m.record.moves) > 0 && !gs.m.gameOver
+	for i := range gs.seats {
+		if gs.seats[i] == id && playing && cfg.Grace > 0 {
+			// held first, so that the seat isn't offered to the queue
+			p := gs.m.players[i]
+			seat, h := i, hold{identity: p.identity, name: p.name, until: time.Now().Add(time.Duration(cfg.Grace))}
+			gs.held[i] = h
+			// the game state rather than a session ends the hold, as no
+			// session may be left to: the opponent may be the bot
+			time.AfterFunc(time.Duration(cfg.Grace), func() { gs.forfeit(seat, h) })
+			defer gs.broadcastLocked(droppedMsg{seat: i, until: h.until})
+		}
+	}
+	gs.unregisterLocked(id)
+}
+
+// reclaimLocked returns the seat held for identity, no longer held, or -1
+// if none is. The caller must hold gs.mu.
+func (gs *gameState) reclaimLocked(identity string) int {
+	for i := range gs.held {
+		if gs.heldLocked(i) && gs.held[i].identity == identity {
+			gs.held[i] = hold{}
+			return i
+		}
+	}
+	return -1
+}
+
+// forfeit ends the hold h on seat once the grace period is over, unless the
+// player came back or the hold ended otherwise. The game, if still on, is
+// won by the opponent, and the seat offered to the queue.
+func (gs *gameState) forfeit(seat int, h hold) {
+	defer gs.saveResults()
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.held[seat] != h {
+		return
+	}
+	gs.held[seat] = hold{}
+	if len(gs.m.record.moves) > 0 && !gs.m.gameOver {
+		m := gs.m
+		m.board = gs.m.board.clone()
+		m.record = gs.m.record.clone()
+		m.gameOver = true
+		m.winner = piece(1 - seat)
+		m.record.won = winDisconnect
+		m.players[1-seat].score++
+		// the result is also the dropped player's, gone from the seat
+		m.players[seat].name = h.name
+		gs.m.players[seat].identity = h.identity
+		gs.commitLocked(m)
+		gs.m.players[seat] = player{score: gs.m.players[seat].score}
+	}
+	gs.broadcastLocked(forfeitMsg{seat: seat})
+	if cfg.Coalesce {
+		gs.dirty = true
+	} else {
+		gs.redrawLocked()
+	}
+	gs.promoteLocked()
+}
+
+// graceLeft returns the seconds left for the player who dropped from seat
+// to come back, and whether they are awaited at all.
+func (m model) graceLeft(seat int) (int, bool) {
+	if m.dropped[seat].IsZero() {
+		return 0, false
+	}
+	left := time.Until(m.dropped[seat])
+	return int(math.Ceil(left.Seconds())), left > 0
+}
+
+// endGrace has the session follow the forfeit of the player who dropped
+// from seat. The opponent's session exports the game and schedules the next,
+// as after any other game it won.
+func (m *model) endGrace(seat int) tea.Cmd {
+	m.dropped[seat] = time.Time{}
+	over := m.gameOver
+	m.gs.Sync(m)
+	if m.spectator || m.self == seat || over || !m.gameOver {
+		return nil
+	}
+	return m.finish()
+}
+
+// graceLines tells the session how long the players who dropped have left
+// to come back.
+func (m model) graceLines(width int) string {
+	v := ""
+	for seat := range m.dropped {
+		if left, ok := m.graceLeft(seat); ok {
+			v += "\n" + truncate(fmt.Sprintf(m.tr("dropped"), m.players[seat].name, left), width)
+		}
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// grace is the -grace of the tests, short for them to wait it out.
+const grace = 100 * time.Millisecond
+
+// waitGrace waits until the grace period is over and the game state is done
+// ending it.
+func (g *testGame) waitGrace() {
+	time.Sleep(2 * grace)
+	g.gs.mu.Lock()
+	defer g.gs.mu.Unlock()
+}
+
+// waitLoss waits for the game state to record a loss of identity, which it
+// does after ending the grace period.
+func waitLoss(t *testing.T, identity string) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+		if ps, ok := scores.GetPlayer(identity); ok && ps.Losses == 1 {
+			return
+		}
+	}
+	ps, _ := scores.GetPlayer(identity)
+	t.Fatalf("%s's record is %+v, want the loss", identity, ps)
+}
+
+func TestGraceForfeitsAgainstBot(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Bot = "random"
+	cfg.BotDelay = 0
+	cfg.Grace = duration(grace)
+	cfg.Spectators = true
+	cfg.WhenFull = "queue"
+	alice := g.connect("alice")
+	alice.press("q")
+	alice.update(tickMsg(time.Now()))
+	g.settle()
+	carol := g.connect("carol")
+	alice.disconnect()
+	g.gs.mu.Lock()
+	over := g.gs.m.gameOver
+	g.gs.mu.Unlock()
+	if over {
+		t.Fatal("the game ended as soon as alice dropped")
+	}
+	// no session but carol's is left, and she is only queued
+	g.waitGrace()
+	g.settle()
+	if !g.gs.m.gameOver || g.gs.m.record.won != winDisconnect || g.gs.m.winner != piece(botSeat) {
+		t.Fatalf("gameOver %v won %q winner %d after the grace period, want a win of the bot by disconnect",
+			g.gs.m.gameOver, g.gs.m.record.won, g.gs.m.winner)
+	}
+	waitLoss(t, "user:alice")
+	if g.gs.held[0].identity != "" {
+		t.Errorf("alice's seat is still held")
+	}
+	if carol.m.spectator || carol.m.self != 0 {
+		t.Errorf("carol wasn't promoted to alice's seat")
+	}
+}
+
+func TestGraceForfeitsToOpponent(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Grace = duration(grace)
+	cfg.AutoReset = duration(time.Minute)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	play(alice, bob, "q", "w")
+	alice.disconnect()
+	if _, ok := bob.m.graceLeft(0); !ok {
+		t.Fatalf("bob isn't told alice may come back")
+	}
+	g.waitGrace()
+	g.settle()
+	if !bob.m.gameOver || bob.m.winner != -1 || bob.m.players[1].score != 1 {
+		t.Fatalf("bob's game: over %v winner %d score %d, want his win", bob.m.gameOver, bob.m.winner, bob.m.players[1].score)
+	}
+	if _, ok := bob.m.graceLeft(0); ok || !bob.m.dropped[0].IsZero() {
+		t.Errorf("bob still waits for alice")
+	}
+	if bob.m.resetIn == 0 {
+		t.Errorf("bob's session didn't schedule the next game")
+	}
+	waitLoss(t, "user:alice")
+	if n := g.gs.stats.wins[winDisconnect]; n != 1 {
+		t.Errorf("%d wins by disconnect counted, want 1", n)
+	}
+}
+
+func TestGraceReconnectKeepsGame(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Grace = duration(grace)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	play(alice, bob, "q", "w")
+	alice.disconnect()
+	again := g.connect("alice")
+	g.waitGrace()
+	g.settle()
+	if g.gs.m.gameOver || again.m.self != 0 || g.gs.seats[0] != again.id {
+		t.Errorf("the game was forfeited although alice came back")
+	}
+	if _, ok := bob.m.graceLeft(0); ok {
+		t.Errorf("bob still waits for alice")
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/bubbletea"
+)
+
+// greeting is the banner of the -greeting file, written to every session
+// before the game starts.
+var greeting string
+
+// loadGreeting reads the banner from the file at path.
+func loadGreeting(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	greeting = strings.TrimRight(string(b), "\n")
+	return nil
+}
+
+// greetMiddleware writes the greeting under the server name to the session
+// and starts the game once a key is pressed or -greetingtime passes. The
+// key skipping it doesn't reach the game.
+func greetMiddleware(next ssh.Handler) ssh.Handler {
+	return func(s ssh.Session) {
+		if greeting == "" {
+			next(s)
+			return
+		}
+		m := model{lang: langFromEnv(sshEnv(s, "LANG"), cfg.Lang)}
+		width := 80
+		if pty, _, ok := s.Pty(); ok && pty.Window.Width > 0 {
+			width = pty.Window.Width
+		}
+		lines := append([]string{cfg.ServerName, ""}, strings.Split(greeting, "\n")...)
+		for i, l := range lines {
+			lines[i] = truncate(l, width)
+		}
+		r := bubbletea.MakeRenderer(s)
+		lines[0] = r.NewStyle().Bold(true).Render(lines[0])
+		lines = append(lines, "", r.NewStyle().Foreground(lipgloss.Color("8")).Render(truncate(m.tr("greetingSkip"), width)))
+		wish.Println(s, strings.Join(lines, "\n"))
+
+		g := &greetedSession{Session: s, ahead: make(chan readAhead, 1)}
+		go g.readAhead()
+		select {
+		case read := <-g.ahead:
+			if read.err != nil {
+				return
+			}
+			next(s)
+		case <-time.After(time.Duration(cfg.GreetingTime)):
+			next(g)
+		case <-s.Context().Done():
+		}
+	}
+}
+
+// readAhead is what a read of the session returned.
+type readAhead struct {
+	b   []byte
+	err error
+}
+
+// greetedSession is a session whose greeting timed out while a read was
+// waiting for the key skipping it. The game gets what that read returns
+// before reading the session itself.
+type greetedSession struct {
+	ssh.Session
+	ahead chan readAhead
+	taken bool   // the read ahead returned
+	rest  []byte // what it read the game hasn't yet
+}
+
+// readAhead reads the session for the key skipping the greeting.
+func (g *greetedSession) readAhead() {
+	b := make([]byte, 256)
+	n, err := g.Session.Read(b)
+	g.ahead <- readAhead{b: b[:n], err: err}
+}
+
+// Read implements io.Reader, returning what was read ahead first.
+func (g *greetedSession) Read(p []byte) (int, error) {
+	if !g.taken {
+		r := <-g.ahead
+		g.taken = true
+		if len(r.b) == 0 && r.err != nil {
+			return 0, r.err
+		}
+		g.rest = r.b
+	}
+	if len(g.rest) > 0 {
+		n := copy(p, g.rest)
+		g.rest = g.rest[n:]
+		return n, nil
+	}
+	return g.Session.Read(p)
+}
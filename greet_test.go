@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// readerSession is a session reading from r, the rest of ssh.Session left
+// unimplemented.
+type readerSession struct {
+	ssh.Session
+	r io.Reader
+}
+
+func (s readerSession) Read(p []byte) (int, error) { return s.r.Read(p) }
+
+func TestGreetedSessionKeepsKeys(t *testing.T) {
+	// two writes land in separate reads, the first one read ahead
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("qw"))
+		pw.Write([]byte("e"))
+		pw.Close()
+	}()
+	g := &greetedSession{Session: readerSession{r: pr}, ahead: make(chan readAhead, 1)}
+	go g.readAhead()
+	var got strings.Builder
+	b := make([]byte, 1)
+	for {
+		n, err := g.Read(b)
+		got.Write(b[:n])
+		if err != nil {
+			break
+		}
+	}
+	if got.String() != "qwe" {
+		t.Errorf("the game read %q after the greeting, want the keys in order, qwe", got.String())
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	goreflect "reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cmdTimeout is how long runCmd waits for a command. Commands waiting for
+// longer are timers, like tick or the cursor blink, and are dropped: tests
+// send the messages of timers themselves.
+const cmdTimeout = 100 * time.Millisecond
+
+// testGame is a game served without SSH. Its sessions are models updated
+// with synthetic messages the way their programs would update them, and
+// see each other's changes through gs like SSH sessions do.
+type testGame struct {
+	t        *testing.T
+	gs       *gameState
+	sessions []*testSession
+}
+
+// testSession is a session of a testGame.
+type testSession struct {
+	g     *testGame
+	id    string
+	m     model
+	inbox chan tea.Msg // messages forwarded by gs
+	quit  bool         // the session quit its program
+}
+
+// newTestGame returns an empty game. The test may change cfg before
+// connecting sessions, it is restored when the test ends, and redraws are
+// sent right away rather than coalesced.
+func newTestGame(t *testing.T) *testGame {
+	t.Helper()
+	saved, savedScores, savedLines := cfg, scores, winLines
+	t.Cleanup(func() {
+		cfg, scores, winLines = saved, savedScores, savedLines
+	})
+	cfg = defaultConfig()
+	cfg.Coalesce = false
+	cfg.StartView = "board"
+	scores = newMemStore()
+	// every test numbers its games from 1
+	atomic.StoreUint64(&gameCount, 0)
+	return &testGame{t: t, gs: newGameState()}
+}
+
+// connect opens a session for the player name, seated in the first free
+// seat or spectating, as teaHandler and programHandler do.
+func (g *testGame) connect(name string) *testSession {
+	g.t.Helper()
+	id := fmt.Sprintf("session-%d", len(g.sessions)+1)
+	p := player{name: name, identity: "user:" + name, width: 60, height: 24}
+	seat, m := g.gs.Join(id, p)
+	if seat >= 0 {
+		m.self = seat
+	} else {
+		if !g.gs.Watch() {
+			g.t.Fatalf("%s: no room to spectate", name)
+		}
+		m.spectator = true
+		m.viewer = p
+		if cfg.WhenFull == "queue" {
+			g.gs.Enqueue(id)
+			m.queued = true
+		}
+	}
+	m.lang = cfg.Lang
+	m.presentation = true
+	m.session = id
+	m.identity = p.identity
+	m.openStartView()
+	m.textInput.CharLimit = cfg.MaxName
+	s := &testSession{g: g, id: id, m: m, inbox: make(chan tea.Msg, 1024)}
+	g.sessions = append(g.sessions, s)
+	g.gs.RegisterSession(id, seat, func(msg tea.Msg) {
+		s.inbox <- msg
+	}, nil)
+	if seat >= 0 {
+		g.gs.BroadcastMessage(joinedMsg{seat: seat, name: name})
+	}
+	g.settle()
+	return s
+}
+
+// settle delivers the messages gs sent to the sessions until none come in
+// for a while.
+func (g *testGame) settle() {
+	g.t.Helper()
+	for idle := 0; idle < 3; {
+		delivered := false
+		for _, s := range g.sessions {
+			for {
+				select {
+				case msg := <-s.inbox:
+					s.update(msg)
+					delivered = true
+					continue
+				default:
+				}
+				break
+			}
+		}
+		if delivered {
+			idle = 0
+			continue
+		}
+		idle++
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// update has the session handle msg and the messages of the commands it
+// returns, without delivering what gs sent to the others.
+func (s *testSession) update(msg tea.Msg) {
+	if s.quit {
+		return
+	}
+	if _, ok := msg.(tea.QuitMsg); ok {
+		// the program ends and with it the SSH session, see programHandler
+		s.quit = true
+		s.g.gs.Drop(s.id)
+		return
+	}
+	next, cmd := s.m.Update(msg)
+	s.m = next.(model)
+	for _, msg := range runCmd(cmd) {
+		s.update(msg)
+	}
+}
+
+// disconnect loses the connection of the session, as when its client
+// goes away without quitting.
+func (s *testSession) disconnect() {
+	s.quit = true
+	s.g.gs.Drop(s.id)
+	s.g.settle()
+}
+
+// press has the session press keys, one after the other, and settles the
+// game after each of them.
+func (s *testSession) press(keys ...string) {
+	for _, k := range keys {
+		s.update(keyMsg(k))
+		s.g.settle()
+	}
+}
+
+// typeText has the session type text, rune by rune.
+func (s *testSession) typeText(text string) {
+	for _, r := range text {
+		s.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	s.g.settle()
+}
+
+// view is the view of the session with the styles stripped.
+func (s *testSession) view() string {
+	return stripANSI(s.m.View())
+}
+
+// keyMsg returns the key press of key, named as by tea.KeyMsg.String.
+func keyMsg(key string) tea.KeyMsg {
+	names := map[string]tea.KeyType{
+		"enter": tea.KeyEnter, "esc": tea.KeyEsc, "tab": tea.KeyTab, " ": tea.KeySpace,
+		"up": tea.KeyUp, "down": tea.KeyDown, "left": tea.KeyLeft, "right": tea.KeyRight,
+		"ctrl+c": tea.KeyCtrlC, "backspace": tea.KeyBackspace, "f1": tea.KeyF1,
+	}
+	if t, ok := names[key]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// runCmd runs cmd and the commands of the batches and sequences it returns,
+// and returns their messages in order. Commands still running after
+// cmdTimeout are dropped, see cmdTimeout.
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	var msg tea.Msg
+	select {
+	case msg = <-done:
+	case <-time.After(cmdTimeout):
+		return nil
+	}
+	if msg == nil {
+		return nil
+	}
+	// tea.Batch and tea.Sequence return lists of commands
+	if v := goreflect.ValueOf(msg); v.Kind() == goreflect.Slice && v.Type().Elem() == goreflect.TypeOf(tea.Cmd(nil)) {
+		var msgs []tea.Msg
+		for i := 0; i < v.Len(); i++ {
+			msgs = append(msgs, runCmd(v.Index(i).Interface().(tea.Cmd))...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+// stripANSI removes the escape sequences of styles from s.
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
+			i += 2
+			for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// play has the players of alice and bob alternate the cells given as keys,
+// alice first.
+func play(alice, bob *testSession, keys ...string) {
+	for i, k := range keys {
+		if i%2 == 0 {
+			alice.press(k)
+		} else {
+			bob.press(k)
+		}
+	}
+}
+
+func TestNameEntry(t *testing.T) {
+	g := newTestGame(t)
+	cfg.StartView = "name"
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	if alice.m.view != 0 || alice.m.focus != focusName {
+		t.Fatalf("alice starts at view %d with focus %d, want the name entry", alice.m.view, alice.m.focus)
+	}
+	if got := alice.m.textInput.Value(); got != "alice" {
+		t.Fatalf("name entry holds %q, want the name to edit", got)
+	}
+	alice.press("backspace", "backspace", "backspace", "backspace", "backspace")
+	alice.typeText("carol")
+	alice.press("enter")
+	if alice.m.view != 1 || alice.m.focus != focusBoard {
+		t.Fatalf("alice is at view %d with focus %d after entering the name, want the board", alice.m.view, alice.m.focus)
+	}
+	if got := bob.m.players[alice.m.self].name; got != "carol" {
+		t.Errorf("bob sees alice as %q, want carol", got)
+	}
+	if got := g.gs.m.players[alice.m.self].name; got != "carol" {
+		t.Errorf("the game has alice as %q, want carol", got)
+	}
+}
+
+func TestFullGame(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	// alice takes the left column, bob blocks nothing
+	play(alice, bob, "q", "w", "a", "s", "z")
+	for _, s := range []*testSession{alice, bob} {
+		if !s.m.gameOver || s.m.winner != 1 {
+			t.Errorf("%s: gameOver %v winner %d, want a win of alice", s.m.me().name, s.m.gameOver, s.m.winner)
+		}
+		if s.m.players[0].score != 1 || s.m.players[1].score != 0 {
+			t.Errorf("%s: scores %d:%d, want 1:0", s.m.me().name, s.m.players[0].score, s.m.players[1].score)
+		}
+	}
+	if !strings.Contains(bob.view(), "alice wins!") {
+		t.Errorf("bob's view doesn't announce the win:\n%s", bob.view())
+	}
+	if ps, ok := scores.GetPlayer("user:alice"); !ok || ps.Wins != 1 {
+		t.Errorf("alice's record is %+v, want a win", ps)
+	}
+}
+
+func TestMenuRecord(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	play(alice, bob, "q", "w", "a", "s")
+	bob.press("2")
+	if v := bob.view(); strings.Contains(v, "Your record") {
+		t.Fatalf("bob's menu shows a record before any game:\n%s", v)
+	}
+	alice.press("z")
+	if v := bob.view(); !strings.Contains(v, "Your record: 0 won · 1 lost · 0 drawn") {
+		t.Errorf("bob's open menu doesn't show the lost game:\n%s", v)
+	}
+}
+
+func TestReset(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	play(alice, bob, "q", "w", "a", "s", "z")
+	first := g.gs.m.record.id
+	bob.press("esc")
+	for _, s := range []*testSession{alice, bob} {
+		if s.m.gameOver || !boardEmpty(s.m.board) {
+			t.Errorf("%s: the board wasn't reset:\n%s", s.m.me().name, s.view())
+		}
+		if s.m.players[0].score != 1 {
+			t.Errorf("%s: alice's score is %d after the reset, want it kept", s.m.me().name, s.m.players[0].score)
+		}
+	}
+	if g.gs.m.record.id == first {
+		t.Errorf("the reset game kept the ID %s of the last one", first)
+	}
+	alice.press("e")
+	if g.gs.m.board[0][2] == 0 {
+		t.Errorf("the new game doesn't take moves")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "fmt"
+
+// hint is the move suggested to a player on request, see -hints.
+type hint struct {
+	game  string // ID of the game the hints were given in
+	moves int    // moves made when the last hint was given
+	x, y  int    // the cell suggested by the last hint
+	used  int    // hints given in the game
+}
+
+// hintsAllowed reports whether the session's player may ask for hints: in
+// practice against the bot, or between people with -pvphints.
+func (m model) hintsAllowed() bool {
+	return cfg.Hints != 0 && !m.spectator && (cfg.Bot != "" || cfg.PvPHints)
+}
+
+// hinted reports whether the last hint is for the move to make now.
+func (m model) hinted() bool {
+	return m.hint.used > 0 && m.hint.game == m.record.id &&
+		m.hint.moves == len(m.record.moves) && !m.gameOver
+}
+
+// askHint suggests the move the minimax bot would make for the player,
+// without making it.
+func (m *model) askHint() {
+	switch {
+	case !m.hintsAllowed():
+		m.notice = m.tr("hintOff")
+		return
+	case m.gameOver || m.flip > 0 || m.waiting() || seat(m.currentPlayer) != m.self:
+		m.notice = m.tr("hintWait")
+		return
+	case m.hinted():
+		return
+	}
+	if m.hint.game != m.record.id {
+		m.hint = hint{game: m.record.id}
+	}
+	if cfg.Hints > 0 && m.hint.used >= cfg.Hints {
+		m.notice = fmt.Sprintf(m.tr("hintNone"), cfg.Hints)
+		return
+	}
+	m.hint.x, m.hint.y = minimaxMove(m.board, m.currentPlayer, m.record.moves, m.gs.Intn)
+	m.hint.moves = len(m.record.moves)
+	m.hint.used++
+}
+
+// hintCell draws the cell of the hint in the player's piece, reversed.
+func (m model) hintCell() string {
+	p := m.me()
+	glyph := string(p.glyph(m.currentPlayer))
+	if p.renderer == nil {
+		return glyph
+	}
+	return p.txtStyle.Copy().Reverse(true).Render(glyph)
+}
@@ -0,0 +1,271 @@
+package main
+
+import "strings"
+
+// defaultLang is the language every other catalog falls back to.
+const defaultLang = "en"
+
+// langs are the languages with a catalog, in the order the settings cycle
+// through them.
+var langs = []string{"en", "ru"}
+
+// catalogs maps a language to its UI strings, keyed by string ID. Strings
+// taking arguments are fmt format strings.
+var catalogs = map[string]map[string]string{
+	"en": {
+		"turn":             "Turn: %c %s",
+		"toMove":           "%c to move",
+		"turnOf":           "%c's turn",
+		"wins":             "%s wins!",
+		"draw":             "Draw!",
+		"coinFlip":         "Flipping a coin… %c",
+		"newGameIn":        "New game in %ds, press any key to skip",
+		"nameAsk":          "Your name?",
+		"chatPrompt":       "say: ",
+		"noCenter":         "The first move may not take the center",
+		"cornerOnly":       "The first move must take a corner",
+		"menuHelp":         "1 board · 0 names · ctrl+c quit",
+		"menuPuzzle":       " · 3 puzzle",
+		"menuSettings":     " · 4 settings",
+		"puzzlePrompt":     "Puzzle of the day: %c to play and win · esc to leave",
+		"puzzleSolved":     "Solved!",
+		"puzzleWrong":      "Not quite, try again",
+		"nameRejected":     "Please pick another name",
+		"moves":            "Moves: %d · %d left",
+		"swapOffer":        "Press y to swap sides instead of answering",
+		"settings":         "Settings",
+		"setCompact":       "l  compact layout: %s",
+		"setPresentation":  "p  presentation: %s",
+		"setLang":          "g  language: %s",
+		"settingsHelp":     "↑/↓ pick · enter change · s save · esc back",
+		"on":               "on",
+		"off":              "off",
+		"prefsSaved":       "Saved",
+		"prefsOff":         "This server doesn't keep settings",
+		"prefsFailed":      "Could not save the settings",
+		"readyUp":          "Press r when you are ready",
+		"readyWait":        "Waiting for opponent to ready up",
+		"readyPlayers":     "Waiting for the players to ready up",
+		"takebackAsked":    "Asked to take back %d moves · u for one more",
+		"takebackOffer":    "%s asks to take back %d moves · y accept · n decline",
+		"takebackDeclined": "The takeback was declined",
+		"takebackNone":     "No more moves to take back",
+		"greetingSkip":     "Press any key to start",
+		"turnLeft":         "%ds left for your move",
+		"tooSmall":         "Terminal too small, please enlarge it to at least %dx%d",
+		"idleWarning":      "Idle, disconnecting in %ds unless you press a key",
+		"gameID":           "game %s",
+		"rung":             "Ladder: %s (%d/%d)",
+		"rungNext":         "win to unlock %s",
+		"rungLast":         "win to complete the ladder",
+		"rungUp":           "You climbed the ladder, next up: %s",
+		"ladderDone":       "Ladder complete!",
+		"rungEasy":         "easy",
+		"rungMedium":       "medium",
+		"rungHard":         "hard",
+		"seatFree":         "A seat is free, press j to take it",
+		"noSeat":           "No seat is free",
+		"recapTitle":       "Last game %s",
+		"recapMoves":       "%d moves in %s",
+		"recapNone":        "No game has finished yet",
+		"recapHelp":        "←/→ step · number and enter to jump · esc back",
+		"recapAt":          "Move %d of %d",
+		"recapJump":        "Jump to move %s",
+		"recapRange":       "Pick a move from 0 to %d",
+		"asciiTerm":        "Drawing in ASCII for your terminal (%s), connect from a compatible one for the full board",
+		"byefull":          "Sorry, both seats are taken and this server has no room for spectators",
+		"byeidle":          "Disconnected for idling, come back any time",
+		"byequit":          "Thanks for playing, bye!",
+		"hintOff":          "No hints in this game",
+		"hintWait":         "Hints are for your move",
+		"hintNone":         "No hints left, you get %d per game",
+		"moveStale":        "The board changed before your move got in, have another look",
+		"byespectators":    "Sorry, the spectator limit is reached, try again later",
+		"quietHours":       "Quiet hours, no new games until %s",
+		"byequiet":         "Quiet hours, back at %s",
+		"reactions":        "Spectators reacted %d times",
+		"setReactions":     "r  spectator reactions: %s",
+		"reactHelp":        "React with ! + ~",
+		"joined":           "%s took a seat",
+		"left":             "%s left their seat",
+		"drawn":            "Game %s was drawn",
+		"wonline":          "Game %s: %s won with a line",
+		"wonresign":        "Game %s: %s won, the opponent resigned",
+		"wondisconnect":    "Game %s: %s won, the opponent didn't come back",
+		"wontimeout":       "Game %s: %s won on time",
+		"wonlimit":         "Game %s: %s won on cells at the move limit",
+		"fullSpectate":     "Both seats are taken, you are watching",
+		"fullQueue":        "Both seats are taken, you are watching as number %d in line for one",
+		"setCursors":       "c  opponent cursor: %s",
+		"failed":           "Something went wrong, sorry. Closing the session…",
+		"byeerror":         "Something went wrong on our side, sorry. Please reconnect",
+		"byebehind":        "Disconnected, the connection couldn't keep up with the game. Please reconnect",
+		"record":           "Your record: %d won · %d lost · %d drawn",
+		"movesLimit":       "Moves: %d · %d left before the limit",
+		"lobby":            "Lobby · b to chat",
+		"dropped":          "%s disconnected, resuming within %ds…",
+		"shareGame":        "%s game %s, move %d",
+		"shareBoard":       "board: %s",
+		"shareWon":         "result: %c %s won",
+		"shareDrawn":       "result: drawn",
+		"shareTurn":        "turn: %c %s",
+		"shareScore":       "score: %s %d, %s %d",
+		"shareHelp":        "any key to close",
+		"nextFirst":        "%s starts the next game",
+		"teachWin":         "Your move %d lost the game, %s would have won it:",
+		"teachDraw":        "Your move %d lost the game, %s would have held the draw:",
+		"expired":          "Game %s ran longer than %s and ended in a draw",
+		"setTheme":         "t  line theme: %s",
+		"setSymbols":       "y  pieces: %s",
+		"setGrid":          "d  grid: %s",
+		"setKeys":          "k  keys: %s",
+		"setBell":          "b  beep on your turn: %s",
+		"renamed":          "%s is now %s",
+		"setRefresh":       "f  refresh at most every: %s",
+		"refreshLive":      "live",
+		"takebackOff":      "Takebacks are off on this server",
+		"clockLeft":        "%ds left on your clock",
+		"notYourTurn":      "It is not your turn",
+		"botThinking":      "%s is thinking…",
+		"setBot":           "o  bot: %s",
+	},
+	"ru": {
+		"turn":             "Ход: %c %s",
+		"toMove":           "Ходит %c",
+		"turnOf":           "ход %c",
+		"wins":             "%s побеждает!",
+		"draw":             "Ничья!",
+		"coinFlip":         "Бросаем монетку… %c",
+		"newGameIn":        "Новая игра через %d с, нажмите любую клавишу",
+		"nameAsk":          "Ваше имя?",
+		"chatPrompt":       "сказать: ",
+		"noCenter":         "Первый ход не может занять центр",
+		"cornerOnly":       "Первый ход должен занять угол",
+		"menuHelp":         "1 доска · 0 имена · ctrl+c выход",
+		"menuPuzzle":       " · 3 задача",
+		"menuSettings":     " · 4 настройки",
+		"puzzlePrompt":     "Задача дня: %c ходит и выигрывает · esc — выйти",
+		"puzzleSolved":     "Решено!",
+		"puzzleWrong":      "Не то, попробуйте ещё",
+		"nameRejected":     "Пожалуйста, выберите другое имя",
+		"moves":            "Ходов: %d · осталось %d",
+		"swapOffer":        "Нажмите y, чтобы поменяться сторонами вместо ответа",
+		"settings":         "Настройки",
+		"setCompact":       "l  компактный вид: %s",
+		"setPresentation":  "p  презентация: %s",
+		"setLang":          "g  язык: %s",
+		"settingsHelp":     "↑/↓ выбор · enter изменить · s сохранить · esc назад",
+		"on":               "вкл",
+		"off":              "выкл",
+		"prefsSaved":       "Сохранено",
+		"prefsOff":         "Этот сервер не хранит настройки",
+		"prefsFailed":      "Не удалось сохранить настройки",
+		"readyUp":          "Нажмите r, когда будете готовы",
+		"readyWait":        "Ждём готовности соперника",
+		"readyPlayers":     "Ждём готовности игроков",
+		"takebackAsked":    "Просим вернуть ходов: %d · u — ещё один",
+		"takebackOffer":    "%s просит вернуть ходов: %d · y да · n нет",
+		"takebackDeclined": "В возврате ходов отказано",
+		"takebackNone":     "Больше нечего возвращать",
+		"greetingSkip":     "Нажмите любую клавишу, чтобы начать",
+		"turnLeft":         "На ход осталось %d с",
+		"tooSmall":         "Терминал слишком мал, увеличьте его хотя бы до %dx%d",
+		"idleWarning":      "Нет активности, отключение через %d с, нажмите любую клавишу",
+		"gameID":           "игра %s",
+		"rung":             "Лестница: %s (%d/%d)",
+		"rungNext":         "победите, чтобы открыть: %s",
+		"rungLast":         "победите, чтобы пройти лестницу",
+		"rungUp":           "Вы поднялись, дальше: %s",
+		"ladderDone":       "Лестница пройдена!",
+		"rungEasy":         "легко",
+		"rungMedium":       "средне",
+		"rungHard":         "сложно",
+		"seatFree":         "Есть свободное место, нажмите j, чтобы сесть",
+		"noSeat":           "Свободных мест нет",
+		"recapTitle":       "Прошлая партия %s",
+		"recapMoves":       "Ходов: %d за %s",
+		"recapNone":        "Ни одна партия ещё не закончилась",
+		"recapHelp":        "←/→ по ходу · номер и enter для перехода · esc назад",
+		"recapAt":          "Ход %d из %d",
+		"recapJump":        "Перейти к ходу %s",
+		"recapRange":       "Выберите ход от 0 до %d",
+		"asciiTerm":        "Ваш терминал (%s) получает доску в ASCII, подключитесь с совместимого для полной доски",
+		"byefull":          "Извините, оба места заняты, а зрителей этот сервер не пускает",
+		"byeidle":          "Отключено из-за бездействия, возвращайтесь в любое время",
+		"byequit":          "Спасибо за игру, пока!",
+		"hintOff":          "В этой игре подсказок нет",
+		"hintWait":         "Подсказки даются на ваш ход",
+		"hintNone":         "Подсказки закончились, их %d на партию",
+		"moveStale":        "Доска изменилась раньше, чем дошёл ваш ход, взгляните ещё раз",
+		"byespectators":    "Извините, зрителей уже слишком много, попробуйте позже",
+		"quietHours":       "Тихие часы, новых партий до %s не будет",
+		"byequiet":         "Тихие часы, возвращайтесь в %s",
+		"reactions":        "Зрители отреагировали %d раз",
+		"setReactions":     "r  реакции зрителей: %s",
+		"reactHelp":        "Реакции: ! + ~",
+		"joined":           "%s занимает место",
+		"left":             "%s освобождает место",
+		"drawn":            "Партия %s: ничья",
+		"wonline":          "Партия %s: %s собирает линию и побеждает",
+		"wonresign":        "Партия %s: %s побеждает, соперник сдался",
+		"wondisconnect":    "Партия %s: %s побеждает, соперник не вернулся",
+		"wontimeout":       "Партия %s: %s побеждает по времени",
+		"wonlimit":         "Партия %s: %s побеждает по клеткам на пределе ходов",
+		"fullSpectate":     "Оба места заняты, вы смотрите игру",
+		"fullQueue":        "Оба места заняты, вы смотрите игру и стоите %d-м в очереди",
+		"setCursors":       "c  курсор соперника: %s",
+		"failed":           "Что-то пошло не так, извините. Сессия закрывается…",
+		"byeerror":         "У нас что-то пошло не так, извините. Подключитесь заново",
+		"byebehind":        "Отключено, соединение не успевало за игрой. Подключитесь заново",
+		"record":           "Ваш счёт: %d побед · %d поражений · %d ничьих",
+		"movesLimit":       "Ходов: %d · до предела %d",
+		"lobby":            "Лобби · b — написать",
+		"dropped":          "%s отключается, ждём возвращения ещё %d с…",
+		"shareGame":        "%s, партия %s, ход %d",
+		"shareBoard":       "доска: %s",
+		"shareWon":         "итог: победа %c %s",
+		"shareDrawn":       "итог: ничья",
+		"shareTurn":        "ход: %c %s",
+		"shareScore":       "счёт: %s %d, %s %d",
+		"shareHelp":        "любая клавиша — закрыть",
+		"nextFirst":        "Следующую партию начинает %s",
+		"teachWin":         "Ваш ход %d проиграл партию, %s вёл к победе:",
+		"teachDraw":        "Ваш ход %d проиграл партию, %s сохранял ничью:",
+		"expired":          "Партия %s шла дольше %s и закончилась вничью",
+		"setTheme":         "t  тема линий: %s",
+		"setSymbols":       "y  фигуры: %s",
+		"setGrid":          "d  сетка: %s",
+		"setKeys":          "k  клавиши: %s",
+		"setBell":          "b  сигнал на ваш ход: %s",
+		"renamed":          "%s теперь %s",
+		"setRefresh":       "f  обновлять не чаще чем: %s",
+		"refreshLive":      "сразу",
+		"takebackOff":      "На этом сервере ходы не возвращают",
+		"clockLeft":        "На часах осталось %d с",
+		"notYourTurn":      "Сейчас не ваш ход",
+		"botThinking":      "%s думает…",
+		"setBot":           "o  бот: %s",
+	},
+}
+
+// tr looks up the UI string id in the session's language, falling back to
+// English for languages or strings without a translation.
+func (m model) tr(id string) string {
+	if s, ok := catalogs[m.lang][id]; ok {
+		return s
+	}
+	return catalogs[defaultLang][id]
+}
+
+// langFromEnv picks a catalog from a locale like "ru_RU.UTF-8", or returns
+// fallback if there is none for it.
+func langFromEnv(locale, fallback string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return fallback
+}
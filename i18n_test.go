@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestTrFallsBackToEnglish(t *testing.T) {
+	catalogs[defaultLang]["testOnly"] = "only in English"
+	t.Cleanup(func() { delete(catalogs[defaultLang], "testOnly") })
+	var m model
+	for _, lang := range []string{"en", "ru", "xx", ""} {
+		m.lang = lang
+		if got := m.tr("testOnly"); got != "only in English" {
+			t.Errorf("%q: tr of a string only in English is %q", lang, got)
+		}
+	}
+	m.lang = "ru"
+	if got, en := m.tr("drawn"), catalogs[defaultLang]["drawn"]; got == en {
+		t.Errorf("tr in Russian fell back to English for a translated string, %q", got)
+	}
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range langs {
+		for id := range catalogs[defaultLang] {
+			if _, ok := catalogs[lang][id]; !ok {
+				t.Errorf("%s has no translation of %s", lang, id)
+			}
+		}
+		for id := range catalogs[lang] {
+			if _, ok := catalogs[defaultLang][id]; !ok {
+				t.Errorf("%s translates %s, which English doesn't have", lang, id)
+			}
+		}
+	}
+}
+
+func TestLangFromEnv(t *testing.T) {
+	for locale, want := range map[string]string{
+		"ru_RU.UTF-8": "ru",
+		"ru":          "ru",
+		"RU_ru":       "ru",
+		"en_US.UTF-8": "en",
+		"de_DE.UTF-8": "fallback",
+		"C":           "fallback",
+		"":            "fallback",
+	} {
+		if got := langFromEnv(locale, "fallback"); got != want {
+			t.Errorf("langFromEnv(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleWarning is how long before disconnecting an idle spectator they are
+// warned, at most.
+const idleWarning = 30 * time.Second
+
+// idleLeft returns the ticks left until an idle spectator is disconnected,
+// see -spectatoridle, and whether the session is subject to it at all.
+// Players are never disconnected for idling.
+func (m model) idleLeft() (int, bool) {
+	if !m.spectator || cfg.SpectatorIdle <= 0 {
+		return 0, false
+	}
+	return ticks(time.Duration(cfg.SpectatorIdle)) - m.idle, true
+}
+
+// tickIdle counts the ticks since the spectator's last key press and
+// disconnects them once they have been idle for too long.
+func (m *model) tickIdle() tea.Cmd {
+	if _, ok := m.idleLeft(); !ok {
+		return nil
+	}
+	m.idle++
+	if left, _ := m.idleLeft(); left > 0 {
+		return nil
+	}
+	return m.quit(reasonIdle)
+}
+
+// idleWarned reports whether the spectator should be warned about being
+// disconnected soon.
+func (m model) idleWarned() bool {
+	left, ok := m.idleLeft()
+	warning := time.Duration(cfg.SpectatorIdle) / 2
+	if warning > idleWarning {
+		warning = idleWarning
+	}
+	return ok && left <= ticks(warning)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// numpadKeys maps the digits to the keys of the cells in reading order, 1
+// for the top left cell to 9 for the bottom right one, see -keys.
+var numpadKeys = map[string]string{
+	"1": "q", "2": "w", "3": "e",
+	"4": "a", "5": "s", "6": "d",
+	"7": "z", "8": "x", "9": "c",
+}
+
+// schemeKey translates a key pressed on the board or a puzzle in scheme,
+// see -keys, to the key of the default scheme. With numpad the digits play
+// the cells and tab opens the menu in place of 2.
+func schemeKey(scheme string, msg tea.KeyMsg) tea.KeyMsg {
+	if scheme != "numpad" {
+		return msg
+	}
+	key := msg.String()
+	if k, ok := numpadKeys[key]; ok {
+		key = k
+	} else if key == "tab" {
+		key = "2"
+	} else {
+		return msg
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// labelMoves is how many moves a player makes before the key labels go,
+// unless they chose to keep them.
+const labelMoves = 3
+
+// keyLabel returns the key playing the cell x, y in scheme, see -keys.
+func keyLabel(scheme string, x, y int) string {
+	if scheme == "numpad" {
+		return strconv.Itoa(x*3 + y + 1)
+	}
+	for k, c := range cellKeys {
+		if c == [2]int{x, y} {
+			return k
+		}
+	}
+	return ""
+}
+
+// toggleLabels shows or hides the key labels for good, see -keylabels.
+func (m *model) toggleLabels() {
+	m.labels = !m.labels
+	m.labelsKept = true
+}
+
+// countMove counts a move of the player, taking the key labels away once
+// they have learned the keys.
+func (m *model) countMove() {
+	m.moved++
+	if m.moved >= labelMoves && !m.labelsKept {
+		m.labels = false
+	}
+}
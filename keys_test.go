@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNumpadKeys(t *testing.T) {
+	for d := 1; d <= 9; d++ {
+		digit := fmt.Sprint(d)
+		x, y := (d-1)/3, (d-1)%3
+		cell, ok := cellKeys[schemeKey("numpad", keyMsg(digit)).String()]
+		if !ok || cell != [2]int{x, y} {
+			t.Errorf("%s plays %v %v, want row %d column %d", digit, cell, ok, x+1, y+1)
+		}
+		if got := keyLabel("numpad", x, y); got != digit {
+			t.Errorf("row %d column %d is labeled %q, want %s", x+1, y+1, got, digit)
+		}
+		if got := schemeKey("qwerty", keyMsg(digit)).String(); got != digit {
+			t.Errorf("qwerty turns %s into %q", digit, got)
+		}
+	}
+	if got := schemeKey("numpad", keyMsg("tab")).String(); got != "2" {
+		t.Errorf("tab is %q with numpad, want the menu's 2", got)
+	}
+}
+
+func TestNumpadPlays(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Keys = "numpad"
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	play(alice, bob, "1", "5", "9")
+	b := g.gs.m.board
+	if b[0][0] != 1 || b[1][1] != -1 || b[2][2] != 1 {
+		t.Errorf("1, 5 and 9 didn't play the diagonal:\n%s", alice.view())
+	}
+	alice.press("tab")
+	if alice.m.view != 2 {
+		t.Errorf("tab leads to view %d, want the menu", alice.m.view)
+	}
+}
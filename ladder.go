@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/log"
+)
+
+// ladder are the bots a player climbs with -ladder, from easy to hard,
+// named by the catalog strings of rungNames.
+var ladder = []string{"random", "greedy", "minimax"}
+
+var rungNames = []string{"rungEasy", "rungMedium", "rungHard"}
+
+// botStrategy is the strategy of the bot the session plays against: the
+// one of the player's rung on the ladder, staying on the last once climbed,
+// or else the difficulty they picked, -bot until they do.
+func (m model) botStrategy() string {
+	if !cfg.Ladder {
+		if m.difficulty != "" {
+			return m.difficulty
+		}
+		return cfg.Bot
+	}
+	if m.rung >= len(ladder) {
+		return ladder[len(ladder)-1]
+	}
+	return ladder[m.rung]
+}
+
+// climb moves the player a rung up after beating the bot, saving the
+// progress with their prefs so that it resumes on reconnect.
+func (m *model) climb() {
+	if !cfg.Ladder || m.spectator || m.winner == 0 || seat(m.winner) != m.self || m.rung >= len(ladder) {
+		return
+	}
+	m.rung++
+	if m.rung < len(ladder) {
+		m.notice = fmt.Sprintf(m.tr("rungUp"), m.tr(rungNames[m.rung]))
+	} else {
+		m.notice = m.tr("ladderDone")
+	}
+	if savedPrefs == nil {
+		return
+	}
+	if err := savedPrefs.Put(m.identity, m.prefs()); err != nil {
+		log.Error("Could not save ladder progress", "path", savedPrefs.path, "error", err)
+	}
+}
+
+// ladderLine shows the player's rung and what it takes to climb on.
+func (m model) ladderLine() string {
+	if m.rung >= len(ladder) {
+		return m.tr("ladderDone")
+	}
+	line := fmt.Sprintf(m.tr("rung"), m.tr(rungNames[m.rung]), m.rung+1, len(ladder))
+	if m.rung+1 < len(ladder) {
+		return line + " · " + fmt.Sprintf(m.tr("rungNext"), m.tr(rungNames[m.rung+1]))
+	}
+	return line + " · " + m.tr("rungLast")
+}
@@ -0,0 +1,94 @@
+package main
+
+import "strings"
+
+// gridStyle is a layout of the grid, see -gridstyle: the spaces either
+// side of each cell, and whether a blank line runs above and below the
+// cells of each row.
+type gridStyle struct {
+	pad  int
+	tall bool
+}
+
+// gridStyles are the layouts selectable with -gridstyle. The compact one
+// packs the cells as tight as they go, the others read better on larger
+// terminals.
+var gridStyles = map[string]gridStyle{
+	"compact":     {},
+	"comfortable": {pad: 1},
+	"spacious":    {pad: 1, tall: true},
+}
+
+// box holds the characters drawing a grid: the corners and junctions of
+// the top, middle and bottom rules, left to right, and the lines.
+type box struct {
+	top, mid, bottom [3]string
+	h, v             string
+}
+
+var (
+	heavyBox = box{
+		top:    [3]string{"┏", "┳", "┓"},
+		mid:    [3]string{"┣", "╋", "┫"},
+		bottom: [3]string{"┗", "┻", "┛"},
+		h:      "━",
+		v:      "┃",
+	}
+	// asciiBox is the grid drawn for terminals kept from box-drawing
+	// characters by -termallow and -termdeny.
+	asciiBox = box{
+		top:    [3]string{"+", "+", "+"},
+		mid:    [3]string{"+", "+", "+"},
+		bottom: [3]string{"+", "+", "+"},
+		h:      "-",
+		v:      "|",
+	}
+)
+
+// cellSize is the width and height a cell takes up inside the lines of
+// the grid in the named style.
+func cellSize(style string) (int, int) {
+	g := gridStyles[style]
+	h := 1
+	if g.tall {
+		h = 3
+	}
+	return 1 + 2*g.pad, h
+}
+
+// draw lays out a grid of rows by cols cells drawn by cell in the named
+// style, the lines of the grid lining up with however wide the cells are
+// padded.
+func (bx box) draw(rows, cols int, style string, cell func(x, y int) string) string {
+	g := gridStyles[style]
+	w, _ := cellSize(style)
+	rule := func(c [3]string) string {
+		parts := make([]string, cols)
+		for i := range parts {
+			parts[i] = strings.Repeat(bx.h, w)
+		}
+		return c[0] + strings.Join(parts, c[1]) + c[2]
+	}
+	blank := rule([3]string{bx.v, bx.v, bx.v})
+	blank = strings.ReplaceAll(blank, bx.h, " ")
+	pad := strings.Repeat(" ", g.pad)
+	lines := []string{rule(bx.top)}
+	for x := 0; x < rows; x++ {
+		if x > 0 {
+			lines = append(lines, rule(bx.mid))
+		}
+		if g.tall {
+			lines = append(lines, blank)
+		}
+		row := bx.v
+		for y := 0; y < cols; y++ {
+			row += pad + cell(x, y) + pad + bx.v
+		}
+		lines = append(lines, row)
+		if g.tall {
+			lines = append(lines, blank)
+		}
+	}
+	lines = append(lines, rule(bx.bottom))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestBoxDraw(t *testing.T) {
+	// two rows of three, the cells told apart
+	cell := func(x, y int) string { return string("XO."[(x+y)%3]) }
+	for _, tt := range []struct {
+		box   string
+		style string
+		want  string
+	}{
+		{"heavy", "compact", `
+┏━┳━┳━┓
+┃X┃O┃.┃
+┣━╋━╋━┫
+┃O┃.┃X┃
+┗━┻━┻━┛`},
+		{"heavy", "comfortable", `
+┏━━━┳━━━┳━━━┓
+┃ X ┃ O ┃ . ┃
+┣━━━╋━━━╋━━━┫
+┃ O ┃ . ┃ X ┃
+┗━━━┻━━━┻━━━┛`},
+		{"heavy", "spacious", `
+┏━━━┳━━━┳━━━┓
+┃   ┃   ┃   ┃
+┃ X ┃ O ┃ . ┃
+┃   ┃   ┃   ┃
+┣━━━╋━━━╋━━━┫
+┃   ┃   ┃   ┃
+┃ O ┃ . ┃ X ┃
+┃   ┃   ┃   ┃
+┗━━━┻━━━┻━━━┛`},
+		{"ascii", "compact", `
++-+-+-+
+|X|O|.|
++-+-+-+
+|O|.|X|
++-+-+-+`},
+		{"ascii", "comfortable", `
++---+---+---+
+| X | O | . |
++---+---+---+
+| O | . | X |
++---+---+---+`},
+		{"ascii", "spacious", `
++---+---+---+
+|   |   |   |
+| X | O | . |
+|   |   |   |
++---+---+---+
+|   |   |   |
+| O | . | X |
+|   |   |   |
++---+---+---+`},
+	} {
+		t.Run(tt.box+" "+tt.style, func(t *testing.T) {
+			bx := heavyBox
+			if tt.box == "ascii" {
+				bx = asciiBox
+			}
+			if got, want := bx.draw(2, 3, tt.style, cell), tt.want[1:]; got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestCellSize(t *testing.T) {
+	for style, want := range map[string][2]int{"compact": {1, 1}, "comfortable": {3, 1}, "spacious": {3, 3}} {
+		if w, h := cellSize(style); w != want[0] || h != want[1] {
+			t.Errorf("%s cells are %dx%d, want %dx%d", style, w, h, want[0], want[1])
+		}
+	}
+}
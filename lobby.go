@@ -0,0 +1,91 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The lobby chat is for the spectators waiting in line for a seat, and with
+// -lobbyspectators for all of them, apart from the chat of the game. The
+// server keeps its last lines for those joining the lobby.
+
+// lobbyMsg is a line of the lobby chat.
+type lobbyMsg chatMsg
+
+// inLobbyLocked reports whether the session id takes part in the lobby
+// chat. The caller must hold gs.mu.
+func (gs *gameState) inLobbyLocked(id string) bool {
+	for _, s := range gs.seats {
+		if s == id {
+			return false
+		}
+	}
+	for _, q := range gs.queue {
+		if q == id {
+			return true
+		}
+	}
+	_, ok := gs.sessions[id]
+	return ok && cfg.LobbySpectators
+}
+
+// Lobby returns the last lines of the lobby chat, for a session joining it.
+func (gs *gameState) Lobby() []chatMsg {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return append([]chatMsg(nil), gs.lobby...)
+}
+
+// SayLobby sends the line msg to the sessions in the lobby and keeps it for
+// those joining later.
+func (gs *gameState) SayLobby(msg chatMsg) tea.Cmd {
+	return func() tea.Msg {
+		gs.mu.Lock()
+		defer gs.mu.Unlock()
+		gs.lobby = append(gs.lobby, msg)
+		if len(gs.lobby) > chatHistory {
+			gs.lobby = gs.lobby[len(gs.lobby)-chatHistory:]
+		}
+		for id, sess := range gs.sessions {
+			if gs.inLobbyLocked(id) {
+				sess.box.post(lobbyMsg(msg))
+			}
+		}
+		return nil
+	}
+}
+
+// inLobby reports whether the session takes part in the lobby chat: a
+// spectator in line for a seat, or any with -lobbyspectators.
+func (m model) inLobby() bool {
+	return m.spectator && (m.queued || cfg.LobbySpectators)
+}
+
+// joinLobby shows the session the last lines of the lobby chat.
+func (m *model) joinLobby() {
+	if m.inLobby() {
+		m.lobby = m.gs.Lobby()
+	}
+}
+
+// leaveLobby clears the lobby chat of a session taking a seat.
+func (m *model) leaveLobby() {
+	m.queued = false
+	m.lobby = nil
+	if m.focus == focusLobby {
+		m.focus = focusBoard
+		m.chatInput.Reset()
+	}
+}
+
+// lobbyView draws the lobby chat of the session, width cells wide, with
+// the input while typing in it.
+func (m model) lobbyView(width int) string {
+	v := "\n" + m.me().faint().Render(truncate(m.tr("lobby"), width))
+	for _, c := range m.lobby {
+		v += "\n" + truncate(c.from+": "+c.text, width)
+	}
+	if m.focus == focusLobby {
+		v += "\n" + m.chatInput.View()
+	}
+	return v
+}
@@ -0,0 +1,92 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// look is how a session draws the board, chosen in the settings view. The
+// fields left empty follow the config.
+type look struct {
+	theme   string // see -theme
+	grid    string // see -gridstyle
+	symbols string // see symbolSets
+	keys    string // see -keys
+}
+
+// symbolSets are the pieces a session can draw the board with. Terminals
+// kept to ASCII draw asciiPieces whatever the set.
+var symbolSets = map[string]map[int]rune{
+	"classic": {1: pieces[1], -1: pieces[-1]},
+	"letters": {1: 'O', -1: 'X'},
+	"shapes":  {1: '●', -1: '■'},
+}
+
+// themeName is the theme p draws the lines through won games in.
+func (p player) themeName() string {
+	if p.look.theme != "" {
+		return p.look.theme
+	}
+	return cfg.Theme
+}
+
+// gridName is the style p lays out the grid in.
+func (p player) gridName() string {
+	if p.look.grid != "" {
+		return p.look.grid
+	}
+	return cfg.GridStyle
+}
+
+// symbolsName is the set p draws the pieces from.
+func (p player) symbolsName() string {
+	if p.look.symbols != "" {
+		return p.look.symbols
+	}
+	return "classic"
+}
+
+// keyScheme is the scheme of the keys p plays the cells with.
+func (p player) keyScheme() string {
+	if p.look.keys != "" {
+		return p.look.keys
+	}
+	return cfg.Keys
+}
+
+// The choices of the settings view, in the order it steps through them.
+var (
+	themeChoices  = []string{"classic", "bold", "strike"}
+	gridChoices   = []string{"compact", "comfortable", "spacious"}
+	symbolChoices = []string{"classic", "letters", "shapes"}
+	keyChoices    = []string{"qwerty", "numpad"}
+)
+
+// nextOf returns the choice after v in list, wrapping around.
+func nextOf(list []string, v string) string {
+	for i, c := range list {
+		if c == v {
+			return list[(i+1)%len(list)]
+		}
+	}
+	return list[0]
+}
+
+// bell is the control character making the terminal beep.
+const bell = "\a"
+
+// tickBell beeps once the turn comes to the player, if they chose to be
+// told so. The turn coming back within a move, like with -doublemove,
+// doesn't count.
+func (m *model) tickBell() tea.Cmd {
+	turn := !m.spectator && !m.gameOver && !m.waiting() && m.flip == 0 &&
+		len(m.record.moves) > 0 && seat(m.currentPlayer) == m.self
+	ring := turn && !m.myTurn && m.bell
+	m.myTurn = turn
+	p := m.me()
+	if !ring || p.renderer == nil {
+		return nil
+	}
+	out := p.renderer.Output()
+	return func() tea.Msg {
+		out.WriteString(bell)
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mailbox queues the messages for the program of a session. Posting never
+// blocks, so a slow or stuck session can't stall the game for everyone,
+// and never drops a message: most carry state the session can't get back,
+// like a chat line or a swap of the seats. Only redraws are coalesced, as
+// the one waiting reads the game as it is once delivered. A session falling
+// mailboxSize messages behind is taken for stuck instead: its mailbox
+// closes and full is called to close the session.
+type mailbox struct {
+	mu     sync.Mutex
+	queue  []tea.Msg
+	closed bool
+	wake   chan struct{} // signalled when a message was queued
+	full   func()        // called once the queue is full, may be nil
+}
+
+// mailboxSize is how many messages a mailbox queues at most.
+const mailboxSize = 1024
+
+func newMailbox(full func()) *mailbox {
+	return &mailbox{wake: make(chan struct{}, 1), full: full}
+}
+
+// post queues msg, unless the mailbox was closed, which it reports. The
+// message filling the queue closes the mailbox and calls full on its own
+// goroutine, as the caller may hold gs.mu.
+func (mb *mailbox) post(msg tea.Msg) bool {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	if mb.closed {
+		return false
+	}
+	if _, ok := msg.(redrawMsg); ok && mb.waiting() {
+		return true
+	}
+	if len(mb.queue) >= mailboxSize {
+		mb.closed = true
+		mb.queue = nil
+		if mb.full != nil {
+			go mb.full()
+		}
+		return false
+	}
+	mb.queue = append(mb.queue, msg)
+	select {
+	case mb.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// waiting reports whether a redraw is queued. The caller must hold mb.mu.
+func (mb *mailbox) waiting() bool {
+	for _, msg := range mb.queue {
+		if _, ok := msg.(redrawMsg); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// take removes the queued messages from the mailbox and returns them.
+func (mb *mailbox) take() []tea.Msg {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	msgs := mb.queue
+	mb.queue = nil
+	return msgs
+}
+
+// close drops the queued messages and any posted later.
+func (mb *mailbox) close() {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.closed = true
+	mb.queue = nil
+}
+
+// forward hands the queued messages to send, in the order they were
+// posted, until done is closed. send may block while the program is busy;
+// the messages posted meanwhile wait in the mailbox.
+func (mb *mailbox) forward(send func(tea.Msg), done <-chan struct{}) {
+	for {
+		select {
+		case <-mb.wake:
+			for _, msg := range mb.take() {
+				send(msg)
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	goreflect "reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMailboxCoalescesOnlyRedraws(t *testing.T) {
+	mb := newMailbox(nil)
+	one, two := chatMsg{from: "alice", text: "one"}, chatMsg{from: "bob", text: "two"}
+	for _, msg := range []tea.Msg{one, redraw(), swapMsg{}, redraw(), two, redraw()} {
+		mb.post(msg)
+	}
+	want := []tea.Msg{one, redraw(), swapMsg{}, two}
+	if got := mb.take(); !goreflect.DeepEqual(got, want) {
+		t.Errorf("mailbox holds %v, want %v", got, want)
+	}
+	mb.post(redraw())
+	if got := mb.take(); len(got) != 1 {
+		t.Errorf("a redraw after the last was taken queued %v, want it alone", got)
+	}
+	mb.close()
+	if mb.post(one) {
+		t.Error("a closed mailbox took a message")
+	}
+}
+
+func TestMailboxFull(t *testing.T) {
+	full := make(chan struct{}, 2)
+	mb := newMailbox(func() { full <- struct{}{} })
+	for i := 0; i < mailboxSize; i++ {
+		if !mb.post(chatMsg{text: fmt.Sprint(i)}) {
+			t.Fatalf("the mailbox refused message %d of %d", i+1, mailboxSize)
+		}
+	}
+	select {
+	case <-full:
+		t.Fatal("full was called before the queue was full")
+	default:
+	}
+	if mb.post(redraw()) {
+		t.Error("a full mailbox took a message")
+	}
+	select {
+	case <-full:
+	case <-time.After(time.Second):
+		t.Fatal("full wasn't called once the queue was full")
+	}
+	if mb.post(redraw()) || len(mb.take()) != 0 {
+		t.Error("the full mailbox still queues messages")
+	}
+	select {
+	case <-full:
+		t.Error("full was called twice")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestBroadcastPassesStuckSession(t *testing.T) {
+	gs := newGameState()
+	stuck, stuckGot := make(chan struct{}), make(chan tea.Msg, 100)
+	gs.RegisterSession("stuck", -1, func(msg tea.Msg) {
+		<-stuck
+		stuckGot <- msg
+	}, nil)
+	got := make(chan tea.Msg, 100)
+	gs.RegisterSession("reading", -1, func(msg tea.Msg) { got <- msg }, nil)
+
+	// many more messages than a session used to buffer
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			gs.BroadcastMessage(chatMsg{text: fmt.Sprint(i)})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("broadcasting blocked on the stuck session")
+	}
+	receive(t, "the reading session", got)
+	// none was dropped for the stuck session, they are all for it once it
+	// reads again
+	close(stuck)
+	receive(t, "the stuck session", stuckGot)
+}
+
+// receive checks that the 100 chat lines broadcast arrive on got in order.
+func receive(t *testing.T, who string, got chan tea.Msg) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		select {
+		case msg := <-got:
+			if want := (chatMsg{text: fmt.Sprint(i)}); msg != want {
+				t.Fatalf("message %d to %s is %v, want %v", i, who, msg, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s got %d of 100 messages", who, i)
+		}
+	}
+}
+
+func TestUnregisterStopsForwarding(t *testing.T) {
+	gs := newGameState()
+	before := runtime.NumGoroutine()
+	// broadcast all along, so that some messages race with the sessions
+	// unregistering
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				gs.BroadcastMessage(redraw())
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprint("session-", i)
+		seat, _ := gs.Join(id, player{name: id})
+		gs.RegisterSession(id, seat, func(tea.Msg) {}, nil)
+		gs.Drop(id)
+		if seat >= 0 && gs.m.players[seat].box != nil {
+			t.Fatalf("seat %d keeps the mailbox of %s", seat+1, id)
+		}
+	}
+	// the broadcasting goroutine is still running
+	if n := goroutinesBack(before + 1); n > before+1 {
+		t.Errorf("%d goroutines after 100 sessions, %d before", n, before)
+	}
+}
@@ -7,9 +7,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -23,84 +27,308 @@ import (
 	"github.com/charmbracelet/wish/activeterm"
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
 )
 
-const (
-	// host = "0.0.0.0"
-	host = "localhost"
-	port = "23234"
-)
-
+// pieces draw the pieces and empty cells. The lines through won games are
+// drawn by the theme, see lineGlyph.
 var pieces = map[int]rune{
 	1:  '○',
 	-1: '×',
-	2:  '-',
-	3:  '|',
-	4:  '\\',
-	5:  '/',
 	0:  ' ',
 }
 
 type player struct {
-	name      string
-	score     int
-	txtStyle  lipgloss.Style
-	quitStyle lipgloss.Style
-	term      string
-	width     int
-	height    int
-	bg        string
-	ch        chan tea.Msg
+	name       string
+	score      int
+	renderer   *lipgloss.Renderer // nil until the player's session connects
+	txtStyle   lipgloss.Style
+	quitStyle  lipgloss.Style
+	emptyStyle lipgloss.Style // fills empty cells, subtle against the background
+	term       string
+	width      int
+	height     int
+	bg         string
+	ascii      bool   // draw with ASCII only, see -termallow
+	identity   string // of the player's session, see identity
+	look       look   // chosen in the settings view
+	box        *mailbox
+}
+
+// focus tells which input receives the key presses of a session.
+type focus int
+
+const (
+	focusBoard focus = iota
+	focusName
+	focusChat
+	focusLobby
+)
+
+// chatHistory is the number of chat lines kept for display.
+const chatHistory = 5
+
+type chatMsg struct {
+	from string
+	text string
+}
+
+// plainRenderer renders without colors. It styles players whose session
+// hasn't set up their own styles, instead of the server's terminal.
+var plainRenderer = func() *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.Ascii)
+	return r
+}()
+
+// text returns the player's text style, falling back to a plain one.
+func (p player) text() lipgloss.Style {
+	if p.renderer == nil {
+		return plainRenderer.NewStyle()
+	}
+	return p.txtStyle
+}
+
+// faint returns the player's style for secondary text, falling back to a
+// plain one.
+func (p player) faint() lipgloss.Style {
+	if p.renderer == nil {
+		return plainRenderer.NewStyle()
+	}
+	return p.quitStyle
 }
 
 type model struct {
-	board         [][]int
+	gs            *gameState // game shared with the other sessions
+	board         board
 	currentPlayer int
 	view          int
+	focus         focus
 	textInput     textinput.Model
+	chatInput     textinput.Model
+	chat          []chatMsg
 	players       [2]player
+	self          int    // index of the player owning this session
+	presentation  bool   // render the big board while spectating, see bigBoard
+	compact       bool   // show turn, scores and result on a single line
+	debug         bool   // draw the connection info over the view
+	lang          string // language of the UI strings
+	record        recording
+	gameOver      bool
+	winner        int      // player who won the finished game, 0 for a draw
+	resetIn       int      // ticks left until the board is reset automatically
+	notice        string   // brief message about the last key press
+	coin          int      // result of the last coin flip
+	flip          int      // ticks left of the coin flip animation
+	buffered      [][2]int // moves made during the coin flip, see buffer
+	puzzle        puzzle   // puzzle being solved in view 3
+	puzzleSolved  bool
+	ready         [2]bool // last readiness of the seats broadcast by gs
+	spectator     bool    // watching the game without a seat
+	viewer        player  // terminal of a spectator session
+	identity      string  // key of the session's saved prefs
+	takeback      takeback
+	turn          turnTimer
+	turnLeft      int // ticks left of the player's turn, see -turntimeout
+	// time left for the player's moves as last counted by gs, see
+	// -gameclock
+	clock         gameClock
+	botMove       turnTimer
+	botLeft       int     // ticks left until the bot plays
+	idle          int     // ticks since the last key press
+	rung          int     // rung of the player on the bot ladder
+	difficulty    string  // strategy picked for the bot, "" for -bot
+	session       string  // ID of the SSH session
+	vacant        [2]bool // seats free for a spectator to take
+	recap         *recap  // game shown by the recap screen, view 5
+	scrub         int     // moves of the recapped game shown
+	jump          string  // move number typed on the recap screen
+	hint          hint
+	cursor        cursor
+	reactions     []reaction // floating until they run out
+	reacted       int        // reactions received, to spread them out
+	reactWait     int        // ticks until the spectator may react again
+	showReactions bool       // the player sees the reactions, not their count
+	labels        bool       // key labels in the empty cells, see -keylabels
+	labelsKept    bool       // the player chose whether to see the labels
+	moved         int        // moves the player made in the session
+	title         string     // terminal title last set, see -title
+	failed        bool       // the session panicked, see recoverUpdate
+	thinking      thinkMsg   // the bot's best move so far, see -botthinking
+	queued        bool       // the spectator is in line for a seat, see -whenfull
+	lobby         []chatMsg  // lines of the lobby chat, see inLobby
+	cursors       [2]cursor  // of the players in the seats, see -opponentcursor
+	hideCursors   bool       // the player doesn't see the opponent's cursor
+	cursorWait    int        // ticks until the cursor may be shared again
+	cursorMoved   bool       // the cursor moved since it was last shared
+	sharing       bool       // the position is shown as text, see shareView
+	lesson        lesson     // of the game the player lost, see -teach
+	setting       int        // row picked in the settings view
+	bell          bool       // beep when the turn comes to the player
+	myTurn        bool       // it was the player's turn at the last tick
+	refresh       duration   // least time between redraws as a spectator
+
+	// dropped holds when the players who dropped forfeit, see -grace
+	dropped [2]time.Time
+
+	// standing is the player's record in the scores, if ranked, see
+	// readStanding
+	standing playerScore
+	ranked   bool
+}
+
+// me is the player, or spectator, owning this session.
+func (m *model) me() *player {
+	if m.spectator {
+		return &m.viewer
+	}
+	return &m.players[m.self]
+}
+
+// move is a single recorded cell press.
+type move struct {
+	x, y int
+	at   time.Time
+}
+
+// recording holds what is needed to replay the current game from scratch.
+type recording struct {
+	id      string // unique to the game while the server runs
+	started time.Time
+	first   int    // player to move first
+	scores  [2]int // scores before the game started
+	moves   []move
+	swapped bool      // the players swapped seats after the first move
+	won     winReason // how the game was won, "" while on or drawn
+	placed  int       // cells placed in the current turn, see -doublemove
+}
+
+// clone returns a copy of r that doesn't share its moves.
+func (r recording) clone() recording {
+	r.moves = append([]move(nil), r.moves...)
+	return r
+}
+
+func newRecording(m model) recording {
+	return recording{
+		id:      newGameID(),
+		started: time.Now(),
+		first:   m.currentPlayer,
+		scores:  [2]int{m.players[0].score, m.players[1].score},
+	}
 }
 
 type gameState struct {
-	players  [2]*ssh.Session
+	seats    [2]string // IDs of the sessions seated, "" for a free seat
 	mu       sync.Mutex
 	m        model
-	sessions map[string]chan tea.Msg
+	clocks   [2]gameClock // of the seats in the game of m, see -gameclock
+	sessions map[string]session
+	ready    [2]bool // seats ready for the current game, see -readycheck
+	takeback takeback
+	dirty    bool // the game changed since the last redraw, see -coalesce
+	last     *recap
+	// spectators counts the sessions watching, see -maxspectators
+	spectators int
+	queue      []string // IDs of the spectators in line for a seat, see -whenfull
+	// quits holds why the sessions that quit did, until their farewell
+	quits map[string]closeReason
+	lobby []chatMsg // last lines of the lobby chat
+	held  [2]hold   // seats kept for dropped players, see -grace
+	// series is the run of games of the players seated, see -rotation
+	series series
+	stats  stats
+	// unsaved are the results of games over waiting to be recorded, see
+	// saveResults
+	unsaved []result
 }
 
-var state = gameState{
-	m:        newBubbleteaModel(),
-	sessions: make(map[string]chan tea.Msg),
+// rng is only used while holding state.mu.
+var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// state is the game served to all SSH sessions.
+var state = newGameState()
+
+func newGameState() *gameState {
+	gs := &gameState{
+		sessions: make(map[string]session),
+		quits:    make(map[string]closeReason),
+		stats:    stats{started: time.Now(), wins: map[winReason]int{}},
+	}
+	gs.m = newBubbleteaModel(gs)
+	return gs
 }
 
-func newBubbleteaModel() model {
+// newBubbleteaModel returns a model of the game gs, starting with an empty
+// board. gs keeps one as the shared game, sessions get a copy from Join.
+func newBubbleteaModel(gs *gameState) model {
 	// initialize tea model
 	ti := textinput.New()
 	ti.Focus()
 	ti.CharLimit = 20
 	ti.Width = 20
-	return model{
-		view:          1,
+	ci := textinput.New()
+	ci.Focus()
+	ci.CharLimit = 80
+	// the shared game; the sessions start at the view of -startview
+	m := model{
+		gs:            gs,
 		currentPlayer: 1,
 		textInput:     ti,
-		board: [][]int{
-			{0, 0, 0},
-			{0, 0, 0},
-			{0, 0, 0},
-		},
+		chatInput:     ci,
+		board:         newBoard(),
 	}
+	m.record = newRecording(m)
+	return m
 }
 
 func main() {
+	if err := setupConfig(); err != nil {
+		log.Fatal("Invalid config", "error", err)
+	}
+	if *verifyPath != "" {
+		if err := verifyCast(*verifyPath); err != nil {
+			log.Warn("Replay doesn't verify", "path", *verifyPath, "error", err)
+			os.Exit(1)
+		}
+		log.Info("Replay verified", "path", *verifyPath)
+		return
+	}
+	log.Info("Loaded config", "config", cfg)
+	if cfg.Bot != "" {
+		state.m.players[botSeat].name = "bot"
+		state.ready[botSeat] = true
+	}
+	if cfg.RandomStart {
+		state.m.currentPlayer = state.FlipCoin()
+		state.m.record.first = state.m.currentPlayer
+	}
+
+	if cfg.Coalesce {
+		go state.flushRedraws(time.Duration(cfg.TickRate))
+	}
+	if cfg.MaxGame > 0 {
+		go state.watchGames()
+	}
+
+	// connections are logged with fields when the log is read by machines
+	logMiddleware := logging.Middleware()
+	if cfg.LogFormat == "json" {
+		logMiddleware = logging.StructuredMiddleware()
+	}
+
 	// start app server
 	s, err := wish.NewServer(
-		wish.WithAddress(net.JoinHostPort(host, port)),
+		wish.WithAddress(net.JoinHostPort(cfg.Host, cfg.Port)),
 		wish.WithHostKeyPath(".ssh/id_ed25519"),
 		wish.WithMiddleware(
-			bubbletea.Middleware(teaHandler),
+			closeMiddleware,
+			bubbletea.MiddlewareWithProgramHandler(programHandler, termenv.Ascii),
+			greetMiddleware,
 			// gameHandler(),
 			activeterm.Middleware(), // Bubble Tea apps usually require a PTY.
-			logging.Middleware(),
+			statsMiddleware,
+			sampleMiddleware(logMiddleware),
 		),
 	)
 	if err != nil {
@@ -109,9 +337,19 @@ func main() {
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	log.Info("Starting SSH server", "host", host, "port", port)
+	serve := s.ListenAndServe
+	if cfg.Socket != "" {
+		l, err := listenSocket(cfg.Socket)
+		if err != nil {
+			log.Fatal("Could not start server", "error", err)
+		}
+		log.Info("Starting SSH server", "socket", cfg.Socket)
+		serve = func() error { return s.Serve(l) }
+	} else {
+		log.Info("Starting SSH server", "host", cfg.Host, "port", cfg.Port)
+	}
 	go func() {
-		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		if err = serve(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
 			log.Error("Could not start server", "error", err)
 			done <- nil
 		}
@@ -124,41 +362,265 @@ func main() {
 	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
 		log.Error("Could not stop server", "error", err)
 	}
+	if cfg.Socket != "" {
+		removeSocket(cfg.Socket)
+	}
 }
 
-// RegisterSession registers a new session to receive updates.
-func (gs *gameState) RegisterSession(id string, ch chan tea.Msg) {
+// session is a connection registered to receive game updates.
+type session struct {
+	box     *mailbox
+	done    chan struct{} // closed when the session unregisters
+	offGame bool          // skipped by redraws, see -redrawfilter
+
+	// throttle spaces out the redraws of a spectator, see SetThrottle
+	throttle time.Duration
+	redrawn  time.Time // when the last redraw was sent
+	deferred bool      // a redraw waits for the throttle to pass
+}
+
+// Join claims the first free seat for the session id and stores p, its
+// player, there. It returns the seat, or -1 once both are taken, with a copy
+// of the game for the session.
+func (gs *gameState) Join(id string, p player) (int, model) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	gs.sessions[id] = ch
-	if gs.m.players[0].ch == nil {
-		gs.m.players[0].ch = ch
-	} else {
-		gs.m.players[1].ch = ch
+	seat := gs.reclaimLocked(p.identity)
+	if seat < 0 {
+		seat = gs.freeSeat()
+		if len(gs.queue) > 0 {
+			// a free seat is kept for the spectator first in line
+			seat = -1
+		}
 	}
+	if seat >= 0 {
+		gs.seats[seat] = id
+		p.score = gs.m.players[seat].score
+		gs.m.players[seat] = p
+	}
+	m := gs.m
+	m.gs = gs
+	m.board = gs.m.board.clone()
+	m.record = gs.m.record.clone()
+	m.ready = gs.ready
+	m.vacant = gs.vacant()
+	return seat, m
+}
 
-	go func() {
-		for {
-			<-ch
-			state.BroadcastMessage(redraw)
-		}
-	}()
+// RegisterSession registers a new session to receive updates, which are
+// forwarded to send, the Send of the session's program, until it
+// unregisters. seat is the seat the session joined, or -1 for a spectator.
+// full is called if the session falls too far behind, see mailbox.
+func (gs *gameState) RegisterSession(id string, seat int, send func(tea.Msg), full func()) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	box, done := newMailbox(full), make(chan struct{})
+	gs.sessions[id] = session{box: box, done: done}
+	defer gs.promoteLocked()
+	if len(gs.sessions) > gs.stats.peak {
+		gs.stats.peak = len(gs.sessions)
+	}
+	if seat >= 0 {
+		gs.m.players[seat].box = box
+	}
+	go box.forward(send, done)
 }
 
-// UnregisterSession removes a session from receiving updates.
+// UnregisterSession removes a session from receiving updates, stops
+// forwarding them to its program and frees its seat.
 func (gs *gameState) UnregisterSession(id string) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
+	gs.unregisterLocked(id)
+}
+
+// unregisterLocked is UnregisterSession for callers already holding gs.mu.
+// Unregistering a session twice is harmless.
+func (gs *gameState) unregisterLocked(id string) {
+	seated := false
+	for i := range gs.seats {
+		if gs.seats[i] == id {
+			gs.seats[i] = ""
+			seated = true
+			// the seat keeps its score for whoever sits down next
+			gs.m.players[i] = player{score: gs.m.players[i].score}
+			gs.endSeriesLocked()
+			gs.broadcastLocked(leftMsg{seat: i})
+		}
+	}
+	gs.dequeueLocked(id)
+	defer gs.promoteLocked()
+	sess, ok := gs.sessions[id]
+	if !ok {
+		return
+	}
+	if !seated {
+		gs.spectators--
+	}
 	delete(gs.sessions, id)
+	close(sess.done)
+	sess.box.close()
+	for i := range gs.m.players {
+		if gs.m.players[i].box == sess.box {
+			gs.m.players[i].box = nil
+			if gs.ready[i] {
+				gs.ready[i] = false
+				gs.broadcastLocked(readyMsg(gs.ready))
+			}
+		}
+	}
 }
 
-// BroadcastMessage sends a message to all registered sessions.
+// BroadcastMessage sends a message to all registered sessions. It never
+// blocks, so a slow or stuck session can't stall the game for everyone,
+// see mailbox.
 func (gs *gameState) BroadcastMessage(msg tea.Msg) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	for _, ch := range gs.sessions {
-		ch <- msg
+	gs.broadcastLocked(msg)
+}
+
+// broadcastLocked is BroadcastMessage for callers already holding gs.mu.
+func (gs *gameState) broadcastLocked(msg tea.Msg) {
+	for _, sess := range gs.sessions {
+		sess.box.post(msg)
+	}
+}
+
+// Commit stores the game state of m as the authoritative copy and returns a
+// command broadcasting a redraw to the sessions, see redrawLocked. With
+// -coalesce the redraw is left to flushRedraws instead.
+func (gs *gameState) Commit(m model) tea.Cmd {
+	defer gs.saveResults()
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.commitLocked(m)
+	if cfg.Coalesce {
+		gs.dirty = true
+		return nil
+	}
+	return func() tea.Msg {
+		gs.mu.Lock()
+		defer gs.mu.Unlock()
+		gs.redrawLocked()
+		return nil
+	}
+}
+
+// commitLocked is Commit without the redraw, for callers holding gs.mu.
+// The result of a game over is left in gs.unsaved for the caller to record
+// with saveResults once it unlocks.
+func (gs *gameState) commitLocked(m model) {
+	if m.gameOver && !gs.m.gameOver {
+		gs.last = newRecap(m)
+		gs.stats.games++
+		result := resultMsg{game: m.record.id, winner: m.winner, reason: m.record.won}
+		if m.winner != 0 {
+			gs.stats.wins[m.record.won]++
+			result.name = m.players[seat(m.winner)].name
+		}
+		log.Info("Game over", "game", result.game, "winner", result.name, "reason", result.reason, "moves", len(m.record.moves))
+		gs.unsaved = append(gs.unsaved, newResult(gs.m.players, m))
+		gs.series.games++
+		gs.series.next = nextFirst(cfg.Rotation, m.record.first, m.winner)
+		checkTimings(gs.m.players, m)
+		gs.broadcastLocked(result)
+	}
+	gs.m.board = m.board.clone()
+	gs.m.currentPlayer = m.currentPlayer
+	gs.m.players[0].score = m.players[0].score
+	gs.m.players[1].score = m.players[1].score
+	gs.m.gameOver = m.gameOver
+	gs.m.winner = m.winner
+	gs.m.record = m.record.clone()
+}
+
+// flushRedraws broadcasts a redraw once per interval if the game changed,
+// so that a burst of changes costs the sessions a single redraw. The last
+// change is always flushed by the interval after it.
+func (gs *gameState) flushRedraws(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for range t.C {
+		gs.flush()
+	}
+}
+
+// flush broadcasts a redraw if the game changed since the last one.
+func (gs *gameState) flush() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.dirty {
+		gs.dirty = false
+		gs.redrawLocked()
+	}
+}
+
+// broadcast returns a command sending msg to all sessions. Sending from the
+// command goroutine keeps a session's Update from blocking on delivering a
+// message to its own program.
+func (gs *gameState) broadcast(msg tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		gs.BroadcastMessage(msg)
+		return nil
+	}
+}
+
+// FlipCoin draws the player starting the next game.
+func (gs *gameState) FlipCoin() int {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	first := 1
+	if rng.Intn(2) == 1 {
+		first = -1
 	}
+	gs.m.currentPlayer = first
+	return first
+}
+
+// Sync checks the game state of m against the authoritative copy and
+// re-syncs m from it if the board, turn, scores or end of the game diverged.
+// A spectator handed a seat sits down in it.
+func (gs *gameState) Sync(m *model) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.promotedLocked(m)
+	if m.board.Equal(gs.m.board) && m.currentPlayer == gs.m.currentPlayer && m.gameOver == gs.m.gameOver &&
+		m.players[0].score == gs.m.players[0].score && m.players[1].score == gs.m.players[1].score {
+		return
+	}
+	log.Warn("Board out of sync, re-syncing", "player", m.me().name, "game", gs.m.record.id)
+	gs.syncLocked(m)
+}
+
+// syncLocked copies the authoritative game state into m. The caller must
+// hold gs.mu.
+func (gs *gameState) syncLocked(m *model) {
+	m.board = gs.m.board.clone()
+	m.currentPlayer = gs.m.currentPlayer
+	m.players[0].score = gs.m.players[0].score
+	m.players[1].score = gs.m.players[1].score
+	m.gameOver = gs.m.gameOver
+	m.winner = gs.m.winner
+	m.record = gs.m.record.clone()
+	m.resetIn = 0
+}
+
+// Play makes the move x, y on the game of m and stores it as the
+// authoritative copy in one go. A move is only made on the position it was
+// chosen on: if another session moved, took back or reset since m last
+// synced, the move is rejected, false, and m re-synced instead.
+func (gs *gameState) Play(m *model, x, y int) bool {
+	defer gs.saveResults()
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if m.record.id != gs.m.record.id || len(m.record.moves) != len(gs.m.record.moves) || m.gameOver != gs.m.gameOver {
+		gs.syncLocked(m)
+		return false
+	}
+	updateCell(m, x, y)
+	gs.commitLocked(*m)
+	return true
 }
 
 // UpdateModel updates the global model and broadcasts the change.
@@ -196,210 +658,939 @@ func (gs *gameState) BroadcastMessage(msg tea.Msg) {
 
 // }
 
-func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
-	// This should never fail, as we are using the activeterm middleware.
-	log.Info("debug", "len", cap(state.players))
+// programHandler creates the program of a session and subscribes it to game
+// updates until the session ends.
+func programHandler(s ssh.Session) *tea.Program {
+	defer recoverSession(s)
+	m, opts := teaHandler(s)
+	if m == nil {
+		return nil
+	}
+	p := tea.NewProgram(m, append(opts, bubbletea.MakeOptions(s)...)...)
 
-	sessionID := s.Context().Value(ssh.ContextKeySessionID).(string)
-	msgCh := make(chan tea.Msg)
-	state.RegisterSession(sessionID, msgCh)
-	defer state.UnregisterSession(sessionID)
+	id := sessionID(s)
+	seat := -1
+	if mm := m.(model); !mm.spectator {
+		seat = mm.self
+	}
+	saveTitle(s)
+	state.RegisterSession(id, seat, p.Send, func() { closeSession(s, reasonBehind) })
+	if seat >= 0 {
+		state.BroadcastMessage(joinedMsg{seat: seat, name: m.(model).players[seat].name})
+	}
+	go func() {
+		<-s.Context().Done()
+		state.Drop(id)
+	}()
+	return p
+}
+
+func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+	// quiet hours only let a game in progress finish
+	if until, ok := quietUntil(time.Now()); ok && !state.Playing() {
+		closeSession(s, reasonQuiet, until.Format("15:04"))
+		return nil, nil
+	}
 
 	// Manage user sessions
-	if state.players[0] == nil {
-		state.players[0] = &s
-		pty, _, _ := s.Pty()
-		renderer := bubbletea.MakeRenderer(s)
-		state.m.players[0].txtStyle = renderer.NewStyle().Foreground(lipgloss.Color("10"))
-		state.m.players[0].quitStyle = renderer.NewStyle().Foreground(lipgloss.Color("8"))
-		state.m.players[0].bg = "light"
-		if renderer.HasDarkBackground() {
-			state.m.players[0].bg = "dark"
-		}
-		state.m.players[0].name = s.User()
-		state.m.players[0].term = pty.Term
-		state.m.players[0].width = pty.Window.Width
-		state.m.players[0].height = pty.Window.Height
-		log.Info("Connected player 1:", "name", s.User())
-	} else if state.players[1] == nil {
-		state.players[1] = &s
-		pty, _, _ := s.Pty()
-		renderer := bubbletea.MakeRenderer(s)
-		state.m.players[1].txtStyle = renderer.NewStyle().Foreground(lipgloss.Color("10"))
-		state.m.players[1].quitStyle = renderer.NewStyle().Foreground(lipgloss.Color("8"))
-		state.m.players[1].bg = "light"
-		if renderer.HasDarkBackground() {
-			state.m.players[1].bg = "dark"
-		}
-		state.m.players[1].name = s.User()
-		state.m.players[1].term = pty.Term
-		state.m.players[1].width = pty.Window.Width
-		state.m.players[1].height = pty.Window.Height
-		log.Info("Connected player 2:", "name", s.User())
-	} else {
-		s.Close()
+	p := newPlayer(s)
+	seat, m := state.Join(sessionID(s), p)
+	switch {
+	case seat >= 0:
+		m.self = seat
+		if connSampled(s) {
+			log.Info(fmt.Sprintf("Connected player %d:", seat+1), connFields(s)...)
+		}
+	case cfg.WhenFull == "close":
+		closeSession(s, reasonFull)
+		return nil, nil
+	case !state.Watch():
+		closeSession(s, reasonSpectators)
+		return nil, nil
+	default:
+		m.spectator = true
+		m.viewer = p
+		if connSampled(s) {
+			log.Info("Connected spectator:", connFields(s)...)
+		}
+	}
+	m.lang = langFromEnv(sshEnv(s, "LANG"), cfg.Lang)
+	if m.spectator {
+		m.notice = m.tr("fullSpectate")
+		if cfg.WhenFull == "queue" {
+			m.notice = fmt.Sprintf(m.tr("fullQueue"), state.Enqueue(sessionID(s)))
+			m.queued = true
+		}
+		m.joinLobby()
+	}
+	if m.me().ascii {
+		m.notice = fmt.Sprintf(m.tr("asciiTerm"), m.me().term)
 	}
-	return state.m, []tea.ProgramOption{tea.WithAltScreen()}
+	m.labels = cfg.KeyLabels && !m.spectator
+	m.presentation = true
+	m.session = sessionID(s)
+	m.identity = identity(s)
+	if savedPrefs != nil {
+		if p, ok := savedPrefs.Get(m.identity); ok {
+			m.applyPrefs(p)
+		}
+	}
+	if m.refresh > 0 {
+		state.SetThrottle(m.session, m.refresh)
+	}
+	m.openStartView()
+	m.chatInput.Prompt = m.tr("chatPrompt")
+	m.textInput.CharLimit = cfg.MaxName
+	m.textInput.Width = cfg.MaxName
+	return m, []tea.ProgramOption{tea.WithAltScreen()}
 }
 
-func updateCell(m *model, x int, y int) {
+// newPlayer sets up the player of s for its terminal.
+func newPlayer(s ssh.Session) player {
+	pty, _, _ := s.Pty()
+	renderer := bubbletea.MakeRenderer(s)
+	p := player{
+		identity:  identity(s),
+		renderer:  renderer,
+		txtStyle:  renderer.NewStyle().Foreground(lipgloss.Color("10")),
+		quitStyle: renderer.NewStyle().Foreground(lipgloss.Color("8")),
+		bg:        "light",
+		name:      sessionName(s),
+		term:      pty.Term,
+		width:     pty.Window.Width,
+		height:    pty.Window.Height,
+		ascii:     !termAllowed(pty.Term),
+	}
+	p.emptyStyle = renderer.NewStyle().Background(lipgloss.Color("254"))
+	if renderer.HasDarkBackground() {
+		p.bg = "dark"
+		p.emptyStyle = renderer.NewStyle().Background(lipgloss.Color("236"))
+	}
+	return p
+}
+
+// sessionID is the ID of s used to register it with the game.
+func sessionID(s ssh.Session) string {
+	return s.Context().SessionID()
+}
+
+// sessionName derives a player name from the SSH user of s, capped to the
+// configured length and filtered.
+func sessionName(s ssh.Session) string {
+	name := []rune(s.User())
+	if len(name) > cfg.MaxName {
+		name = name[:cfg.MaxName]
+	}
+	return filterText(string(name))
+}
+
+// sshEnv returns the value of the environment variable key sent by the
+// client of s, if any.
+func sshEnv(s ssh.Session, key string) string {
+	for _, kv := range s.Environ() {
+		if strings.HasPrefix(kv, key+"=") {
+			return kv[len(key)+1:]
+		}
+	}
+	return ""
+}
+
+// updateCell applies a press on cell x, y and reports whether it won the game.
+func updateCell(m *model, x int, y int) bool {
+	m.record.moves = append(m.record.moves, move{x: x, y: y, at: time.Now()})
+	if x == pass {
+		// a pass gives up what is left of the turn
+		m.currentPlayer *= -1
+		m.record.placed = 0
+		checkLimit(m)
+		return false
+	}
 	var cell = &m.board[x][y]
 	if *cell == 0 {
-		*cell = m.currentPlayer
-		m.currentPlayer *= -1
+		place(m, cell)
 	} else if *cell == 1 || *cell == -1 {
 		*cell *= -1
 	}
-	// check if row is the same player
-	var victory = false
-	if m.board[x][0] == m.board[x][1] && m.board[x][1] == m.board[x][2] {
-		m.board[x][0] = 2
-		m.board[x][1] = 2
-		m.board[x][2] = 2
-		victory = true
-	}
-	// check if column is the same player
-	if m.board[0][y] == m.board[1][y] && m.board[1][y] == m.board[2][y] {
-		m.board[0][y] = 3
-		m.board[1][y] = 3
-		m.board[2][y] = 3
-		victory = true
-	}
-	// check if diagonal is the same player
-	if m.board[0][0] == m.board[1][1] && m.board[1][1] == m.board[2][2] {
-		if m.board[1][1] == 1 || m.board[1][1] == -1 {
-			m.board[0][0] = 4
-			m.board[1][1] = 4
-			m.board[2][2] = 4
-			victory = true
-		}
-	}
-	// Check secondary diagonal
-	if m.board[0][2] == m.board[1][1] && m.board[1][1] == m.board[2][0] {
-		if m.board[1][1] == 1 || m.board[1][1] == -1 {
-			m.board[0][2] = 5
-			m.board[1][1] = 5
-			m.board[2][0] = 5
-			victory = true
+	// the winner owns the completed lines; take it before they are
+	// struck through, as the turn may have passed on. With -doublemove
+	// the first placement of a turn can win already.
+	owner := winner(m.board)
+	for _, l := range completedLines(m.board) {
+		for _, c := range l {
+			m.board[c[0]][c[1]] = l.mark()
 		}
 	}
+	victory := owner != 0
 	if victory {
-		if m.currentPlayer != 1 {
-			m.players[0].score++
+		// one point per game, however many lines the move completed
+		m.gameOver = true
+		m.winner = owner
+		m.record.won = winLine
+		m.players[seat(owner)].score++
+	} else if boardFull(m.board) {
+		m.gameOver = true
+		m.winner = 0
+	} else {
+		checkLimit(m)
+	}
+	return victory
+}
+
+// checkLimit ends the game of m once it took -movelimit moves, passes
+// included, see limitWinner.
+func checkLimit(m *model) {
+	if cfg.MoveLimit == 0 || len(m.record.moves) < cfg.MoveLimit {
+		return
+	}
+	m.gameOver = true
+	m.winner = limitWinner(m.board)
+	if m.winner != 0 {
+		m.record.won = winLimit
+		m.players[seat(m.winner)].score++
+	}
+}
+
+// limitWinner decides a game stopped by -movelimit: drawn, or with
+// -onlimit pieces won by the piece holding more cells.
+func limitWinner(b board) int {
+	if cfg.OnLimit != "pieces" {
+		return 0
+	}
+	n := 0
+	for _, row := range b {
+		for _, c := range row {
+			n += c
+		}
+	}
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// piece returns the piece played from seat, the inverse of seat.
+func piece(seat int) int {
+	if seat == 1 {
+		return -1
+	}
+	return 1
+}
+
+// seat returns the index into model.players of the player using piece.
+func seat(piece int) int {
+	if piece == -1 {
+		return 1
+	}
+	return 0
+}
+
+// openingViolation checks a move on x, y against the configured opening
+// rule and returns the ID of the message rejecting it, if it's disallowed.
+func openingViolation(b board, x int, y int) string {
+	if !boardEmpty(b) {
+		return ""
+	}
+	corner := (x == 0 || x == len(b)-1) && (y == 0 || y == len(b[x])-1)
+	switch cfg.Opening {
+	case "nocenter":
+		if x == len(b)/2 && y == len(b[x])/2 {
+			return "noCenter"
+		}
+	case "corner":
+		if !corner {
+			return "cornerOnly"
+		}
+	}
+	return ""
+}
+
+// boardEmpty reports whether no cell of b has been played yet.
+func boardEmpty(b board) bool {
+	return b.CountEmpty() == b.cells()
+}
+
+// boardFull reports whether no empty cell is left on b.
+func boardFull(b board) bool {
+	return b.CountEmpty() == 0
+}
+
+// press plays cell x, y.
+func (m *model) press(x int, y int) tea.Cmd {
+	if m.gameOver || m.flip > 0 || m.waiting() {
+		return nil
+	}
+	if cfg.StrictTurns && seat(m.currentPlayer) != m.self {
+		m.notice = m.tr("notYourTurn")
+		return nil
+	}
+	if id := openingViolation(m.board, x, y); id != "" {
+		m.notice = m.tr(id)
+		return nil
+	}
+	if !m.gs.Play(m, x, y) {
+		m.notice = m.tr("moveStale")
+		return nil
+	}
+	m.countMove()
+	return m.finish()
+}
+
+// finish commits the move just made. Once the game is over it is exported
+// and, if enabled, an automatic reset of the board is scheduled.
+func (m *model) finish() tea.Cmd {
+	commit := tea.Batch(m.gs.Commit(*m), m.gs.ClearTakeback(false))
+	if !m.gameOver {
+		return commit
+	}
+	m.climb()
+	if cfg.CastDir != "" {
+		name := m.record.started.Format("20060102-150405") + "-" + m.record.id + ".cast"
+		path := filepath.Join(cfg.CastDir, name)
+		if err := exportCast(*m, path); err != nil {
+			log.Error("Could not export game", "game", m.record.id, "path", path, "error", err)
 		} else {
-			m.players[1].score++
+			log.Info("Exported game", "game", m.record.id, "path", path)
 		}
 	}
+	if cfg.AutoReset <= 0 {
+		return commit
+	}
+	m.resetIn = ticks(time.Duration(cfg.AutoReset))
+	return commit
 }
 
-type redrawMsg string
+// reset clears the board and starts recording a new game.
+func (m *model) reset() {
+	m.board = newBoard()
+	m.gameOver = false
+	m.winner = 0
+	m.resetIn = 0
+	if first := m.gs.NextFirst(); first != 0 {
+		m.currentPlayer = first
+	}
+	m.record = newRecording(*m)
+}
 
-func redraw() tea.Msg {
-	return redrawMsg("")
+// startGame commits the freshly reset game of m. With a random start the
+// starting player is drawn once for all sessions and announced by a coin flip.
+func (m *model) startGame() tea.Cmd {
+	// requests and readiness belong to the previous game
+	clear := m.gs.ClearTakeback(false)
+	if cfg.ReadyCheck {
+		m.ready = [2]bool{}
+		clear = tea.Batch(clear, m.gs.ClearReady())
+	}
+	if !cfg.RandomStart {
+		return tea.Batch(m.gs.Commit(*m), clear)
+	}
+	first := m.gs.FlipCoin()
+	m.currentPlayer = first
+	m.record.first = first
+	return tea.Batch(tea.Sequence(m.gs.Commit(*m), m.gs.broadcast(coinFlipMsg{first: first})), clear)
+}
+
+// coinFrames is the number of faces shown by the coin flip animation, each for
+// coinFrameTime.
+const (
+	coinFrames    = 10
+	coinFrameTime = 150 * time.Millisecond
+)
+
+// coinFlipMsg announces the starting player drawn for a new game.
+type coinFlipMsg struct {
+	first int
+}
+
+// coinFace is the face of the coin shown in the current animation frame. The
+// faces alternate so that the last frame lands on the drawn player.
+func (m model) coinFace() int {
+	frame := (m.flip + ticks(coinFrameTime) - 1) / ticks(coinFrameTime)
+	if frame%2 == 1 {
+		return m.coin
+	}
+	return -m.coin
 }
 
 // ---------- Bubbletea functions -------------
 func (m model) Init() tea.Cmd {
 	// return textinput.Blink
-	return nil
+	return tick()
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m model) Update(msg tea.Msg) (next tea.Model, cmd tea.Cmd) {
+	defer m.recoverUpdate(msg, &next, &cmd)
+	defer m.trackGame(m.offGame(), &next)
 	switch msg := msg.(type) {
 	case redrawMsg:
-		m.players[0].txtStyle.Render(m.View())
+		m.gs.Sync(&m)
+		return m, nil
+	case tickMsg:
+		var drained tea.Cmd
+		if m.flip > 0 {
+			m.flip--
+			if m.flip == 0 {
+				drained = m.drain()
+			}
+		}
+		if m.resetIn > 0 && m.gameOver {
+			m.resetIn--
+			if m.resetIn == 0 && !m.quietBlocks() {
+				m.reset()
+				return m, tea.Batch(tick(), m.startGame())
+			}
+		}
+		m.tickReactions()
+		return m, tea.Batch(tick(), drained, m.tickTurn(), m.tickBot(), m.tickIdle(), m.tickTitle(), m.tickCursor(), m.tickBell())
+	case swapMsg:
+		m.swapSeats()
+		return m, nil
+	case takebackMsg:
+		m.takeback = msg.takeback
+		if msg.declined {
+			m.notice = m.tr("takebackDeclined")
+		}
+		return m, nil
+	case reactionMsg:
+		m.addReaction(msg)
+		return m, nil
+	case droppedMsg:
+		// the seat is held, not free, see leftMsg
+		m.vacant[msg.seat] = false
+		m.dropped[msg.seat] = msg.until
+		return m, nil
+	case forfeitMsg:
+		return m, m.endGrace(msg.seat)
+	case renamedMsg:
+		m.players[msg.seat].name = msg.name
+		m.announce(announceMsg{id: "renamed", args: []interface{}{msg.from, msg.name}})
+		return m, nil
+	case joinedMsg:
+		m.vacant[msg.seat] = false
+		m.dropped[msg.seat] = time.Time{}
+		m.players[msg.seat].name = msg.name
+		m.announce(announceMsg{id: "joined", args: []interface{}{msg.name}})
+		return m, nil
+	case cursorMsg:
+		m.cursors[msg.seat] = msg.cursor
+		return m, nil
+	case thinkMsg:
+		m.thinking = msg
+		return m, nil
+	case leftMsg:
+		m.vacant[msg.seat] = true
+		m.cursors[msg.seat] = cursor{}
+		m.announce(announceMsg{id: "left", args: []interface{}{m.players[msg.seat].name}})
+		return m, nil
+	case announceMsg:
+		m.announce(msg)
+		return m, nil
+	case promoteMsg:
+		m.gs.Sync(&m)
+		return m, nil
+	case resultMsg:
+		m.announceResult(msg)
+		return m, m.teach(msg)
+	case recordedMsg:
+		m.readStanding()
+		return m, nil
+	case expiredMsg:
+		return m, m.leaveExpired(msg)
+	case lessonMsg:
+		m.lesson = lesson(msg)
+		return m, nil
+	case readyMsg:
+		m.ready = msg
+		return m, nil
+	case coinFlipMsg:
+		if !cfg.Animations {
+			return m, nil
+		}
+		m.coin = msg.first
+		m.flip = coinFrames * ticks(coinFrameTime)
+		m.buffered = nil
 		return m, nil
 	case tea.WindowSizeMsg:
-		m.players[0].height = msg.Height
-		m.players[0].width = msg.Width
+		m.me().height = msg.Height
+		m.me().width = msg.Width
+	case lobbyMsg:
+		if m.inLobby() {
+			m.lobby = append(m.lobby, chatMsg(msg))
+			if len(m.lobby) > chatHistory {
+				m.lobby = m.lobby[len(m.lobby)-chatHistory:]
+			}
+		}
+		return m, nil
+	case chatMsg:
+		m.chat = append(m.chat, msg)
+		if len(m.chat) > chatHistory {
+			m.chat = m.chat[len(m.chat)-chatHistory:]
+		}
+		return m, nil
 	case tea.KeyMsg:
+		m.idle = 0
+		// ctrl+c quits from every view, even while typing
+		if msg.String() == "ctrl+c" {
+			return m, m.quit(reasonQuit)
+		}
+		// any key dismisses the shared position
+		if m.sharing {
+			m.sharing = false
+			return m, nil
+		}
+		// while typing, keys never reach the board
+		if m.focus != focusBoard {
+			return m.updateInput(msg)
+		}
+		if msg.String() == "f1" {
+			m.debug = !m.debug
+			return m, nil
+		}
+		if m.view == 1 || m.view == 3 {
+			msg = schemeKey(m.me().keyScheme(), msg)
+		}
+		// spectators watch and chat, but don't touch the game; the game
+		// keys mean something else away from the board
+		if m.spectator && m.view == 1 && changesGame(msg.String()) {
+			return m, nil
+		}
 		switch m.view {
-		case 0:
-			switch msg.String() {
-			case "enter":
-				switch m.currentPlayer {
-				case 1:
-					m.players[0].name = m.textInput.Value()
-					m.textInput.Placeholder = "Player 2 name?"
-					m.textInput.Reset()
-					m.currentPlayer *= -1
-				case -1:
-					m.players[1].name = m.textInput.Value()
-					m.currentPlayer *= -1
-					m.view = 1
+		case 1:
+			offered := m.takeback.plies > 0 && m.takeback.from != m.self
+			// any key skips the countdown of a pending automatic reset,
+			// except for taking back the last move
+			if m.resetIn > 0 && msg.String() != "u" && !offered {
+				if m.quietBlocks() {
+					return m, nil
 				}
-			default:
-				var cmd tea.Cmd
-				m.textInput, cmd = m.textInput.Update(msg)
-				return m, cmd
+				m.reset()
+				return m, m.startGame()
+			}
+			var cmd tea.Cmd
+			m.notice = ""
+			if cell, ok := cellKeys[msg.String()]; ok {
+				if m.flip > 0 {
+					m.buffer(cell[0], cell[1])
+					return m, nil
+				}
+				return m, m.press(cell[0], cell[1])
 			}
-		case 1:
 			switch msg.String() {
-			case "ctrl+c":
-				return m, tea.Quit
-			case "q":
-				updateCell(&m, 0, 0)
-			case "w":
-				updateCell(&m, 0, 1)
-			case "e":
-				updateCell(&m, 0, 2)
-			case "a":
-				updateCell(&m, 1, 0)
-			case "s":
-				updateCell(&m, 1, 1)
-			case "d":
-				updateCell(&m, 1, 2)
-			case "z":
-				updateCell(&m, 2, 0)
-			case "x":
-				updateCell(&m, 2, 1)
-			case "c":
-				updateCell(&m, 2, 2)
+			case "y":
+				if offered {
+					return m, m.acceptTakeback()
+				}
+				if m.canSwap() {
+					return m, m.gs.Swap()
+				}
+			case "n":
+				if offered {
+					return m, m.gs.ClearTakeback(true)
+				}
+			case "u":
+				return m, m.askTakeback()
+			case "h":
+				m.askHint()
+			case "k":
+				m.toggleLabels()
+			case "up":
+				return m, m.moveCursor(-1, 0)
+			case "down":
+				return m, m.moveCursor(1, 0)
+			case "left":
+				return m, m.moveCursor(0, -1)
+			case "right":
+				return m, m.moveCursor(0, 1)
+			case "enter", " ":
+				if m.cursor.shown {
+					return m, m.press(m.cursor.x, m.cursor.y)
+				}
+			case "!", "+", "~":
+				if m.spectator {
+					return m, m.react(msg.String())
+				}
+			case "v":
+				if !m.spectator {
+					return m, m.leaveSeat()
+				}
+			case "j":
+				if m.spectator {
+					return m, m.takeSeat()
+				}
+			case "r":
+				if cfg.ReadyCheck && !m.ready[m.self] {
+					return m, m.gs.SetReady(m.self)
+				}
+			case "o":
+				m.sharing = true
+			case "t":
+				m.focus = focusChat
+			case "b":
+				if m.inLobby() {
+					m.focus = focusLobby
+				}
+			case "p":
+				if m.spectator {
+					m.presentation = !m.presentation
+				}
+			case "l":
+				m.compact = !m.compact
 			case "0":
-				m.view = 0
+				m.openNameEntry()
 			case "1":
 				m.view = 1
 			case "2":
-				m.view = 2
+				m.openMenu()
+			case "3":
+				if len(puzzles) > 0 {
+					m.startPuzzle()
+				}
+			case "g":
+				m.openRecap()
 			case "esc":
-				m.board = [][]int{
-					{0, 0, 0},
-					{0, 0, 0},
-					{0, 0, 0},
+				if !m.quietBlocks() {
+					m.reset()
+					cmd = m.startGame()
 				}
 			}
 			// state.BroadcastMessage(redraw)
 			// m.players[0].ch <- "0"
-			return m, nil
+			return m, cmd
+		case 2:
+			switch msg.String() {
+			case "0":
+				m.openNameEntry()
+			case "1":
+				m.view = 1
+			case "3":
+				if len(puzzles) > 0 {
+					m.startPuzzle()
+				}
+			case "4":
+				m.view = 4
+				m.notice = ""
+			}
+		case 3:
+			m.notice = ""
+			return m.updatePuzzle(msg.String()), nil
+		case 4:
+			return m.updateSettings(msg.String())
+		case 5:
+			return m.updateRecap(msg.String())
 		}
 	}
 	return m, nil
 }
 
-//	func (m model) View() string {
-//		s := fmt.Sprintf("Your term is %s\nYour window size is %dx%d\nBackground: %s\n", m.term, m.width, m.height, m.bg)
-//		return m.txtStyle.Render(s) + "\n\n" + m.quitStyle.Render("Press 'q' to quit\n")
-//	}
-func (m model) View() string {
-	v := "Tik-Tag-Go"
+// changesGame reports whether key plays, resets or renames on the board.
+func changesGame(key string) bool {
+	_, ok := cellKeys[key]
+	switch key {
+	case "esc", "r", "y", "n", "u", "0", "enter", " ":
+		return true
+	}
+	return ok
+}
+
+// updateInput routes a key press to the focused text input. Apart from esc,
+// which leaves the input, and enter, which submits it, every key is typed.
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// drop what was typed into the input in focus; the name entry
+		// goes back to the name in use
+		if m.focus == focusName {
+			m.view = 1
+			m.textInput.SetValue(m.me().name)
+		} else {
+			m.chatInput.Reset()
+		}
+		m.focus = focusBoard
+		return m, nil
+	case "enter":
+		if m.focus == focusChat {
+			text := m.chatInput.Value()
+			m.chatInput.Reset()
+			m.focus = focusBoard
+			if text == "" {
+				return m, nil
+			}
+			// share the position in the encoding of parseBoard
+			if text == "/board" {
+				text = formatBoard(m.board)
+			}
+			return m, m.gs.Say(m, chatMsg{from: m.me().name, text: filterText(text)})
+		}
+		if m.focus == focusLobby {
+			text := m.chatInput.Value()
+			m.chatInput.Reset()
+			m.focus = focusBoard
+			if text == "" {
+				return m, nil
+			}
+			return m, m.gs.SayLobby(chatMsg{from: m.me().name, text: filterText(text)})
+		}
+		m.notice = ""
+		name := m.textInput.Value()
+		if filterText(name) != name {
+			m.notice = m.tr("nameRejected")
+			m.textInput.Reset()
+			return m, nil
+		}
+		m.view = 1
+		m.focus = focusBoard
+		if name = strings.TrimSpace(name); name == "" || name == m.me().name {
+			return m, nil
+		}
+		m.me().name = name
+		return m, m.gs.Rename(m.session, name)
+	}
+	var cmd tea.Cmd
+	if m.focus == focusChat || m.focus == focusLobby {
+		m.chatInput, cmd = m.chatInput.Update(msg)
+	} else {
+		m.textInput, cmd = m.textInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m model) View() (v string) {
+	defer m.recoverView(&v)
+	if m.failed {
+		return m.tr("failed")
+	}
+	if v, ok := m.tooSmall(); ok {
+		return v
+	}
+	if m.debug {
+		return overlay(m.render(), m.debugView())
+	}
+	if m.sharing {
+		return m.shareView()
+	}
+	return m.render()
+}
+
+// render draws the current view of the session.
+func (m model) render() string {
+	v := cfg.ServerName
 	switch m.view {
 	case 0:
-		v = m.textInput.View()
+		v = truncate(m.tr("nameAsk"), m.me().width) + "\n" + m.textInput.View()
+		if m.notice != "" {
+			v += "\n" + truncate(m.notice, m.me().width)
+		}
+	case 2:
+		v = m.menuView()
+	case 3:
+		v = m.puzzleView()
+	case 4:
+		v = m.settingsView()
+	case 5:
+		v = m.recapView()
 	case 1:
-		v = fmt.Sprintf("%s: %d\n%s: %d\n┏━┳━┳━┓\n┃%c┃%c┃%c┃\n┣━╋━╋━┫\n┃%c┃%c┃%c┃\n┣━╋━╋━┫\n┃%c┃%c┃%c┃\n┗━┻━┻━┛",
-			m.players[0].name,
-			m.players[0].score,
-			m.players[1].name,
-			m.players[1].score,
-			pieces[m.board[0][0]],
-			pieces[m.board[0][1]],
-			pieces[m.board[0][2]],
-			pieces[m.board[1][0]],
-			pieces[m.board[1][1]],
-			pieces[m.board[1][2]],
-			pieces[m.board[2][0]],
-			pieces[m.board[2][1]],
-			pieces[m.board[2][2]])
+		if m.bigBoard() {
+			return m.presentationView()
+		}
+		width := m.me().width
+		turn := m.players[0].name
+		if m.currentPlayer == -1 {
+			turn = m.players[1].name
+		}
+		if m.compact {
+			v = m.statusLine(width) + "\n" + m.boardGrid()
+		} else {
+			v = nameLine(m.players[0], width) + "\n" +
+				nameLine(m.players[1], width) + "\n" +
+				m.turnLine(turn, width) + "\n" +
+				m.boardGrid()
+			if !m.gameOver && cfg.MoveLimit > 0 {
+				n := len(m.record.moves)
+				v += "\n" + truncate(fmt.Sprintf(m.tr("movesLimit"), n, cfg.MoveLimit-n), width)
+			} else if !m.gameOver {
+				left := m.board.CountEmpty()
+				v += "\n" + truncate(fmt.Sprintf(m.tr("moves"), m.board.cells()-left, left), width)
+			}
+		}
+		if m.notice != "" {
+			v += "\n" + truncate(m.notice, width)
+		}
+		if m.turnLeft > 0 {
+			v += "\n" + truncate(fmt.Sprintf(m.tr("turnLeft"), seconds(m.turnLeft)), width)
+		}
+		if m.botLeft > 0 {
+			v += "\n" + truncate(fmt.Sprintf(m.tr("botThinking"), m.players[botSeat].name), width)
+		}
+		if left := m.clockLeft(); left > 0 {
+			v += "\n" + truncate(fmt.Sprintf(m.tr("clockLeft"), seconds(left)), width)
+		}
+		v += m.graceLines(width)
+		if cfg.Ladder && !m.spectator {
+			v += "\n" + truncate(m.ladderLine(), width)
+		}
+		if m.spectator && (m.vacant[0] || m.vacant[1]) {
+			v += "\n" + truncate(m.tr("seatFree"), width)
+		}
+		if l := m.reactionLine(width); l != "" {
+			v += "\n" + l
+		}
+		if m.idleWarned() {
+			left, _ := m.idleLeft()
+			v += "\n" + truncate(fmt.Sprintf(m.tr("idleWarning"), seconds(left)), width)
+		}
+		if m.takeback.plies > 0 {
+			v += "\n" + truncate(m.takebackLine(), width)
+		} else if m.canSwap() {
+			v += "\n" + truncate(m.tr("swapOffer"), width)
+		}
+		if m.gameOver {
+			if !m.compact {
+				v += "\n" + truncate(m.result(), width)
+			}
+			v += m.lessonLines(width)
+			if line, ok := m.nextFirstLine(); ok {
+				v += "\n" + truncate(line, width)
+			}
+			if m.resetIn > 0 {
+				v += "\n" + truncate(fmt.Sprintf(m.tr("newGameIn"), seconds(m.resetIn)), width)
+			}
+		}
+		for _, c := range m.chat {
+			if c.from == "" {
+				// a line of the server, see announce
+				v += "\n" + m.me().faint().Render(truncate(c.text, width))
+				continue
+			}
+			v += "\n" + truncate(c.from+": "+c.text, width)
+		}
+		if m.focus == focusChat {
+			v += "\n" + m.chatInput.View()
+		}
+		if m.inLobby() {
+			v += m.lobbyView(width)
+		}
+		v += "\n" + m.me().faint().Render(truncate(fmt.Sprintf(m.tr("gameID"), m.record.id), width))
 	}
 	return v
 }
+
+// cellKeys maps the keys playing a cell to its row and column.
+var cellKeys = map[string][2]int{
+	"q": {0, 0}, "w": {0, 1}, "e": {0, 2},
+	"a": {1, 0}, "s": {1, 1}, "d": {1, 2},
+	"z": {2, 0}, "x": {2, 1}, "c": {2, 2},
+}
+
+// grid draws the cells of b with box-drawing characters, filling the empty
+// ones as set up for p.
+func (p player) grid(b board) string {
+	return p.frame(func(x, y int) string {
+		return p.cell(b, x, y)
+	})
+}
+
+// cell draws the cell x, y of b.
+func (p player) cell(b board, x, y int) string {
+	if b[x][y] == 0 {
+		return p.emptyCell(x, y)
+	}
+	if r, ok := p.lineGlyph(b[x][y]); ok {
+		return p.lineCell(r)
+	}
+	return string(p.glyph(b[x][y]))
+}
+
+// frame draws the grid around the cells drawn by cell.
+func (p player) frame(cell func(x, y int) string) string {
+	bx := heavyBox
+	if p.ascii {
+		bx = asciiBox
+	}
+	return bx.draw(3, 3, p.gridName(), cell)
+}
+
+// emptyCell draws the empty cell x, y with the -emptyfill of the config.
+func (p player) emptyCell(x int, y int) string {
+	blank := string(p.glyph(0))
+	if p.renderer == nil {
+		return blank
+	}
+	switch cfg.EmptyFill {
+	case "solid":
+		return p.emptyStyle.Render(blank)
+	case "checker":
+		if (x+y)%2 == 0 {
+			return p.emptyStyle.Render(blank)
+		}
+	}
+	return blank
+}
+
+// nameLine renders a player's name and score, truncating the name so that
+// the whole line fits into width. A width of zero means no limit.
+func nameLine(p player, width int) string {
+	score := fmt.Sprintf(": %d", p.score)
+	if width <= 0 {
+		return p.name + score
+	}
+	budget := width - lipgloss.Width(score)
+	if budget < 1 {
+		budget = 1
+	}
+	return truncate(p.name, budget) + score
+}
+
+// truncate cuts s down to width cells, replacing the tail with an ellipsis.
+// A width of zero or less means no limit.
+func truncate(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return plainRenderer.NewStyle().MaxWidth(width-1).Render(s) + "…"
+}
+
+// turnLine tells whose turn it is, or shows the coin flip deciding it.
+func (m model) turnLine(turn string, width int) string {
+	if m.flip > 0 {
+		return truncate(fmt.Sprintf(m.tr("coinFlip"), m.me().glyph(m.coinFace())), width)
+	}
+	if m.waiting() {
+		return truncate(m.readyLine(), width)
+	}
+	return truncate(fmt.Sprintf(m.tr("turn"), m.me().glyph(m.currentPlayer), turn), width)
+}
+
+// statusLine combines scores and the turn or result into a single line,
+// like "○ Alice 2 · × Bob 1 · ○'s turn".
+func (m model) statusLine(width int) string {
+	status := fmt.Sprintf(m.tr("turnOf"), m.me().glyph(m.currentPlayer))
+	switch {
+	case m.flip > 0:
+		status = fmt.Sprintf(m.tr("coinFlip"), m.me().glyph(m.coinFace()))
+	case m.waiting():
+		status = m.readyLine()
+	case m.gameOver:
+		status = m.result()
+	}
+	line := fmt.Sprintf("%c %s %d · %c %s %d · %s",
+		m.me().glyph(1), m.players[0].name, m.players[0].score,
+		m.me().glyph(-1), m.players[1].name, m.players[1].score,
+		status)
+	return m.me().text().Render(truncate(line, width))
+}
+
+// result describes how the finished game ended.
+func (m model) result() string {
+	switch m.winner {
+	case 1:
+		return fmt.Sprintf(m.tr("wins"), m.players[0].name)
+	case -1:
+		return fmt.Sprintf(m.tr("wins"), m.players[1].name)
+	}
+	return m.tr("draw")
+}
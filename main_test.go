@@ -0,0 +1,189 @@
+package main
+
+import (
+	goreflect "reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestMoveLimitCountsPasses(t *testing.T) {
+	g := newTestGame(t)
+	cfg.MoveLimit = 3
+	m := g.gs.m
+	for i := 0; i < 3; i++ {
+		if m.gameOver {
+			t.Fatalf("the game ended after %d passes, want %d", i, cfg.MoveLimit)
+		}
+		updateCell(&m, pass, pass)
+	}
+	if !m.gameOver || m.winner != 0 {
+		t.Errorf("gameOver %v winner %d after 3 passes, want a draw by the limit", m.gameOver, m.winner)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"alexandria", 0, "alexandria"},
+		{"alexandria", 20, "alexandria"},
+		{"alexandria", 10, "alexandria"},
+		{"alexandria", 9, "alexandr…"},
+		{"alexandria", 5, "alex…"},
+		{"alexandria", 1, "…"},
+		// wide runes take two columns each
+		{"名前の長いプレイヤー", 9, "名前の長…"},
+	} {
+		if got := truncate(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestLongNamesFitWidth(t *testing.T) {
+	for _, width := range []int{16, 24, 40} {
+		g := newTestGame(t)
+		alice := g.connect(strings.Repeat("a", 20))
+		bob := g.connect(strings.Repeat("b", 20))
+		play(alice, bob, "q")
+		alice.update(tea.WindowSizeMsg{Width: width, Height: 24})
+		named := 0
+		for _, line := range strings.Split(alice.view(), "\n") {
+			if strings.Contains(line, "aaaa") || strings.Contains(line, "bbbb") {
+				named++
+				if w := lipgloss.Width(line); w > width {
+					t.Errorf("width %d: the line %q takes %d columns", width, line, w)
+				}
+			}
+		}
+		if named < 2 {
+			t.Errorf("width %d: %d lines name the players, want both:\n%s", width, named, alice.view())
+		}
+	}
+}
+
+// goroutinesBack waits for the number of goroutines to fall back to want,
+// as those of sessions gone exit in their own time, and returns the last
+// count.
+func goroutinesBack(want int) int {
+	n := runtime.NumGoroutine()
+	for deadline := time.Now().Add(time.Second); n > want && time.Now().Before(deadline); n = runtime.NumGoroutine() {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return n
+}
+
+func TestSessionsLeaveNoGoroutines(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Spectators = true
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		alice := g.connect("alice")
+		bob := g.connect("bob")
+		carol := g.connect("carol")
+		play(alice, bob, "q")
+		// one quits, the others lose their connections
+		alice.press("ctrl+c")
+		bob.disconnect()
+		carol.disconnect()
+		g.sessions = nil
+	}
+	if n := goroutinesBack(before); n > before {
+		t.Errorf("%d goroutines after 10 rounds of sessions, %d before", n, before)
+	}
+}
+
+func TestOpeningRestrictions(t *testing.T) {
+	// the cells of the empty board allowed as the first move, by key
+	for _, tt := range []struct {
+		opening string
+		allowed string
+	}{
+		{"", "qweasdzxc"},
+		{"nocenter", "qweadzxc"},
+		{"corner", "qezc"},
+	} {
+		t.Run(tt.opening, func(t *testing.T) {
+			for k, c := range cells {
+				if k == "pass" {
+					continue
+				}
+				g := newTestGame(t)
+				cfg.Opening = tt.opening
+				alice := g.connect("alice")
+				bob := g.connect("bob")
+				alice.press(k)
+				played := g.gs.m.board[c[0]][c[1]] != 0
+				if want := strings.Contains(tt.allowed, k); played != want {
+					t.Errorf("opening on %s played %v, want %v", k, played, want)
+				}
+				if played {
+					continue
+				}
+				if alice.m.notice == "" {
+					t.Errorf("opening on %s was rejected without a notice", k)
+				}
+				// the rule only binds the first move
+				alice.press(string(tt.allowed[0]))
+				bob.press(k)
+				if g.gs.m.board[c[0]][c[1]] != -1 {
+					t.Errorf("the answer on %s was rejected", k)
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentMoves(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		g := newTestGame(t)
+		alice, bob := g.connect("alice"), g.connect("bob")
+		// both chose their move on the empty board, alice the corner and bob
+		// the center, or both the corner
+		cells := [][2]int{{0, 0}, {1, 1}}
+		if round%2 == 1 {
+			cells[1] = cells[0]
+		}
+		models := []model{alice.m, bob.m}
+		played := make([]bool, 2)
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := range models {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				played[i] = g.gs.Play(&models[i], cells[i][0], cells[i][1])
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+		if played[0] == played[1] {
+			t.Fatalf("round %d: alice's move made %v and bob's %v, want exactly one", round, played[0], played[1])
+		}
+		won := 0
+		if played[1] {
+			won = 1
+		}
+		b := g.gs.m.board
+		if n := 9 - len(emptyCells(b)); n != 1 || len(g.gs.m.record.moves) != 1 {
+			t.Fatalf("round %d: %d pieces and %d moves after the race, want one of each", round, n, len(g.gs.m.record.moves))
+		}
+		if b[cells[won][0]][cells[won][1]] != 1 || g.gs.m.currentPlayer != -1 {
+			t.Errorf("round %d: the move made isn't the one reported", round)
+		}
+		// the rejected session is back on the position that won
+		lost := models[1-won]
+		if !goreflect.DeepEqual(lost.board, b) || lost.currentPlayer != g.gs.m.currentPlayer {
+			t.Errorf("round %d: the rejected session wasn't re-synced", round)
+		}
+	}
+}
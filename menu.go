@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultServerName is the plain name of the game, shown where the logo
+// doesn't fit unless -servername brands the server otherwise.
+const defaultServerName = "Tik-Tag-Go"
+
+const defaultLogo = `█████ █ █  █     █████  ██   ███      ███  ██
+  █   █ █ █        █   █  █ █        █    █  █
+  █   █ ██   ███   █   ████ █ ██ ███ █ ██ █  █
+  █   █ █ █        █   █  █ █  █     █  █ █  █
+  █   █ █  █       █   █  █  ███      ███  ██`
+
+// logo is the art shown on the menu screen.
+var logo = defaultLogo
+
+// loadLogo replaces the built-in logo with the art in the file at path.
+func loadLogo(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	logo = strings.TrimRight(string(b), "\n")
+	return nil
+}
+
+// openMenu shows the menu with the player's record as the scores have it.
+func (m *model) openMenu() {
+	m.view = 2
+	m.readStanding()
+}
+
+// readStanding reads the player's record from the scores, once when the
+// menu opens and again after every game recorded, rather than on every
+// render of the menu.
+func (m *model) readStanding() {
+	m.standing, m.ranked = scores.GetPlayer(m.identity)
+}
+
+// menuView renders the menu screen with the logo centered in the session.
+func (m model) menuView() string {
+	p := m.me()
+	art := cfg.ServerName
+	if cfg.ShowLogo && (p.width <= 0 || lipgloss.Width(logo) <= p.width) {
+		art = logo
+	}
+	help := m.tr("menuHelp")
+	if len(puzzles) > 0 {
+		help += m.tr("menuPuzzle")
+	}
+	help += m.tr("menuSettings")
+	v := p.text().Render(art) + "\n\n"
+	if ps := m.standing; m.ranked {
+		v += fmt.Sprintf(m.tr("record"), ps.Wins, ps.Losses, ps.Draws) + "\n"
+	}
+	v += p.faint().Render(help)
+	if p.width <= 0 {
+		return v
+	}
+	return lipgloss.PlaceHorizontal(p.width, lipgloss.Center, v)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The game state broadcasts a message of its own type for every kind of
+// change, so that each session handles them apart in Update:
+//
+//   - redrawMsg: the game changed, see Commit
+//   - chatMsg: a session said something in the chat
+//   - announceMsg: the server tells everyone something, in the chat
+//   - resultMsg: a game finished
+//   - recordedMsg: the scores recorded the result of a game
+//   - joinedMsg, leftMsg: a player took or gave up a seat
+//   - renamedMsg: a player changed their name
+//
+// Features coordinating the sessions add their own: coinFlipMsg, readyMsg,
+// swapMsg, takebackMsg, reactionMsg, cursorMsg, thinkMsg, droppedMsg and forfeitMsg.
+// All of them reach every session they are sent to, see mailbox; only a
+// redraw may stand in for others.
+
+// redrawMsg tells the sessions to redraw the game from the shared state.
+type redrawMsg struct{}
+
+func redraw() tea.Msg {
+	return redrawMsg{}
+}
+
+// announceMsg is a line of the server in the chat, the UI string id with
+// args, in the language of each session.
+type announceMsg struct {
+	id   string
+	args []interface{}
+}
+
+// resultMsg tells the sessions how the game finished: won by the player
+// winner, the piece, or drawn.
+type resultMsg struct {
+	game   string
+	winner int
+	name   string // of the winner
+	reason winReason
+}
+
+// recordedMsg tells the sessions that the scores recorded the result of a
+// game. It follows the resultMsg of the game once saveResults is done, so
+// the sessions read their record again without racing the write.
+type recordedMsg struct{}
+
+// joinedMsg tells the sessions that the player name took seat.
+type joinedMsg struct {
+	seat int
+	name string
+}
+
+// renamedMsg tells the sessions that the player of seat changed their name
+// from one to name.
+type renamedMsg struct {
+	seat       int
+	from, name string
+}
+
+// leftMsg tells the sessions that the player of seat gave it up.
+type leftMsg struct {
+	seat int
+}
+
+// announce adds the line of msg to the chat of the session.
+func (m *model) announce(msg announceMsg) {
+	m.chat = append(m.chat, chatMsg{text: fmt.Sprintf(m.tr(msg.id), msg.args...)})
+	if len(m.chat) > chatHistory {
+		m.chat = m.chat[len(m.chat)-chatHistory:]
+	}
+}
+
+// announceResult announces the result of msg in the chat.
+func (m *model) announceResult(msg resultMsg) {
+	if msg.winner == 0 {
+		m.announce(announceMsg{id: "drawn", args: []interface{}{msg.game}})
+		return
+	}
+	m.announce(announceMsg{id: "won" + string(msg.reason), args: []interface{}{msg.game, msg.name}})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+)
+
+// A panic in a session ends that session only: it is logged with its
+// context, the player gets an apology and the others play on. Deferred
+// calls release gs.mu on the way, so the game isn't locked up either.
+
+// recoverUpdate, deferred by Update, turns a panic handling msg into the
+// error screen and quits the session.
+func (m model) recoverUpdate(msg tea.Msg, next *tea.Model, cmd *tea.Cmd) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	log.Error("Session panicked", "session", m.session, "msg", fmt.Sprintf("%T", msg), "panic", r, "stack", string(debug.Stack()))
+	m.failed = true
+	*next, *cmd = m, m.quit(reasonError)
+}
+
+// recoverView, deferred by View, draws the error screen in place of a view
+// that panicked.
+func (m model) recoverView(v *string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	log.Error("View panicked", "session", m.session, "view", m.view, "panic", r, "stack", string(debug.Stack()))
+	*v = m.tr("failed")
+}
+
+// recoverSession, deferred by programHandler, closes s with an apology if
+// setting up its program panicked.
+func recoverSession(s ssh.Session) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	log.Error("Session setup panicked", append(connFields(s), "panic", r, "stack", string(debug.Stack()))...)
+	closeSession(s, reasonError)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// panicStore is a Store failing with a panic on every result.
+type panicStore struct{ *memStore }
+
+func (panicStore) RecordResult(ids, names [2]string, winner int, won winReason) error {
+	panic("store broke")
+}
+
+func TestPanicInUpdateEndsOnlyThatSession(t *testing.T) {
+	// the result is recorded by Play for the winning move and by Commit for
+	// a forfeit, once they unlocked gs.mu
+	for name, keys := range map[string][]string{
+		"win":     {"q", "w", "a", "s", "z"},
+		"forfeit": {"q", "w", "v"},
+	} {
+		t.Run(name, func(t *testing.T) { testPanicInUpdate(t, keys) })
+	}
+}
+
+func testPanicInUpdate(t *testing.T, keys []string) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	scores = panicStore{newMemStore()}
+	play(alice, bob, keys...)
+	if !alice.m.failed || !alice.quit {
+		t.Fatalf("alice's session didn't fail and quit after the panic")
+	}
+	if !strings.Contains(alice.view(), alice.m.tr("failed")) {
+		t.Errorf("alice doesn't get the apology:\n%s", alice.view())
+	}
+	if !g.gs.mu.TryLock() {
+		t.Fatal("the panic left gs.mu locked")
+	}
+	g.gs.mu.Unlock()
+	if g.gs.seats[0] != "" {
+		t.Errorf("alice's seat is still taken by %q", g.gs.seats[0])
+	}
+	// bob plays on with whoever sits down next
+	scores = newMemStore()
+	carol := g.connect("carol")
+	bob.press("esc")
+	play(carol, bob, "q", "w")
+	if g.gs.m.board[0][0] != 1 || g.gs.m.board[0][1] != -1 {
+		t.Errorf("the game doesn't go on after the panic:\n%s", bob.view())
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+)
+
+// prefs are the settings a player keeps between sessions.
+type prefs struct {
+	Lang         string `json:"lang,omitempty"`
+	Compact      bool   `json:"compact"`
+	Presentation bool   `json:"presentation"`
+	Ladder       int    `json:"ladder,omitempty"` // rung on the bot ladder
+	Difficulty   string `json:"difficulty,omitempty"`
+	Reactions    bool   `json:"reactions"`
+	HideCursors  bool   `json:"hideCursors"`
+	Labels       *bool  `json:"labels,omitempty"` // nil unless chosen with k
+	// the look of the board, "" for the server's
+	Theme   string `json:"theme,omitempty"`
+	Grid    string `json:"grid,omitempty"`
+	Symbols string `json:"symbols,omitempty"`
+	Keys    string `json:"keys,omitempty"`
+	Bell    bool   `json:"bell"`
+	// Refresh throttles the redraws while spectating, see refreshChoices
+	Refresh duration `json:"refresh,omitempty"`
+}
+
+// prefStore keeps the prefs of every identity in a JSON file.
+type prefStore struct {
+	mu    sync.Mutex
+	path  string
+	prefs map[string]prefs
+}
+
+// savedPrefs is the store of the -prefs file, nil if there is none.
+var savedPrefs *prefStore
+
+// loadPrefs reads the prefs stored at path. A missing file starts an empty
+// store; a corrupt one is logged and replaced on the next save.
+func loadPrefs(path string) (*prefStore, error) {
+	ps := &prefStore{path: path, prefs: map[string]prefs{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &ps.prefs); err != nil {
+		log.Warn("Ignoring corrupt preferences", "path", path, "error", err)
+		ps.prefs = map[string]prefs{}
+	}
+	return ps, nil
+}
+
+// Get returns the prefs saved for id.
+func (ps *prefStore) Get(id string) (prefs, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p, ok := ps.prefs[id]
+	return p, ok
+}
+
+// Put saves p for id, replacing the file with writeFile.
+func (ps *prefStore) Put(id string, p prefs) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.prefs[id] = p
+	b, err := json.MarshalIndent(ps.prefs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(ps.path, b)
+}
+
+// writeFile replaces the file at path with b, writing a temporary file
+// first so that a crash can't leave it half written.
+func writeFile(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// identity names the player of s for stored settings: the fingerprint of
+// their public key, or their user name if they didn't offer one.
+func identity(s ssh.Session) string {
+	if pk := s.PublicKey(); pk != nil {
+		sum := sha256.Sum256(pk.Marshal())
+		return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+	}
+	return "user:" + s.User()
+}
+
+// prefs returns the current settings of the session.
+func (m model) prefs() prefs {
+	p := prefs{Lang: m.lang, Compact: m.compact, Presentation: m.presentation, Ladder: m.rung, Reactions: m.showReactions, HideCursors: m.hideCursors}
+	l := m.me().look
+	p.Theme, p.Grid, p.Symbols, p.Keys, p.Bell = l.theme, l.grid, l.symbols, l.keys, m.bell
+	p.Refresh = m.refresh
+	p.Difficulty = m.difficulty
+	if m.labelsKept {
+		labels := m.labels
+		p.Labels = &labels
+	}
+	return p
+}
+
+// applyPrefs switches the session to the settings of p.
+func (m *model) applyPrefs(p prefs) {
+	if _, ok := catalogs[p.Lang]; ok {
+		m.lang = p.Lang
+	}
+	m.compact = p.Compact
+	m.presentation = p.Presentation
+	m.showReactions = p.Reactions
+	m.hideCursors = p.HideCursors
+	if p.Labels != nil {
+		m.labels, m.labelsKept = *p.Labels, true
+	}
+	if p.Ladder >= 0 && p.Ladder <= len(ladder) {
+		m.rung = p.Ladder
+	}
+	if _, ok := strategies[p.Difficulty]; ok {
+		m.difficulty = p.Difficulty
+	}
+	// choices this server doesn't offer any more fall back to its own
+	l := &m.me().look
+	if _, ok := themes[p.Theme]; ok {
+		l.theme = p.Theme
+	}
+	if _, ok := gridStyles[p.Grid]; ok {
+		l.grid = p.Grid
+	}
+	if _, ok := symbolSets[p.Symbols]; ok {
+		l.symbols = p.Symbols
+	}
+	if p.Keys == "qwerty" || p.Keys == "numpad" {
+		l.keys = p.Keys
+	}
+	m.bell = p.Bell
+	for _, d := range refreshChoices {
+		if d == p.Refresh {
+			m.refresh = d
+		}
+	}
+}
+
+// nextLang returns the language after lang in the order of langs.
+func nextLang(lang string) string {
+	for i, l := range langs {
+		if l == lang {
+			return langs[(i+1)%len(langs)]
+		}
+	}
+	return defaultLang
+}
+
+// setting is a row of the settings view: the key changing it, the UI
+// string showing its value and how pressing the key changes it.
+type setting struct {
+	key    string
+	id     string
+	value  func(m model) string
+	change func(m *model)
+	shown  func(m model) bool // nil if always shown
+}
+
+// onOff shows a setting that is either on or off.
+func (m model) onOff(b bool) string {
+	if b {
+		return m.tr("on")
+	}
+	return m.tr("off")
+}
+
+// settings are the rows of the settings view, in order. Every change shows
+// at once, in the settings view itself and the views behind it.
+var settings = []setting{
+	{key: "l", id: "setCompact",
+		value:  func(m model) string { return m.onOff(m.compact) },
+		change: func(m *model) { m.compact = !m.compact }},
+	{key: "p", id: "setPresentation",
+		value:  func(m model) string { return m.onOff(m.presentation) },
+		change: func(m *model) { m.presentation = !m.presentation },
+		shown:  func(m model) bool { return m.spectator }},
+	{key: "g", id: "setLang",
+		value: func(m model) string { return m.lang },
+		change: func(m *model) {
+			m.lang = nextLang(m.lang)
+			m.chatInput.Prompt = m.tr("chatPrompt")
+		}},
+	{key: "r", id: "setReactions",
+		value:  func(m model) string { return m.onOff(m.showReactions) },
+		change: func(m *model) { m.showReactions = !m.showReactions }},
+	{key: "c", id: "setCursors",
+		value:  func(m model) string { return m.onOff(!m.hideCursors) },
+		change: func(m *model) { m.hideCursors = !m.hideCursors },
+		shown:  func(m model) bool { return cfg.OpponentCursor }},
+	{key: "t", id: "setTheme",
+		value:  func(m model) string { return m.me().themeName() },
+		change: func(m *model) { m.me().look.theme = nextOf(themeChoices, m.me().themeName()) }},
+	{key: "y", id: "setSymbols",
+		value:  func(m model) string { return m.me().symbolsName() },
+		change: func(m *model) { m.me().look.symbols = nextOf(symbolChoices, m.me().symbolsName()) }},
+	{key: "d", id: "setGrid",
+		value:  func(m model) string { return m.me().gridName() },
+		change: func(m *model) { m.me().look.grid = nextOf(gridChoices, m.me().gridName()) }},
+	{key: "k", id: "setKeys",
+		value:  func(m model) string { return m.me().keyScheme() },
+		change: func(m *model) { m.me().look.keys = nextOf(keyChoices, m.me().keyScheme()) }},
+	{key: "b", id: "setBell",
+		value:  func(m model) string { return m.onOff(m.bell) },
+		change: func(m *model) { m.bell = !m.bell }},
+	{key: "f", id: "setRefresh",
+		value: func(m model) string { return m.refreshName(m.refresh) },
+		change: func(m *model) {
+			m.refresh = nextRefresh(m.refresh)
+			m.gs.SetThrottle(m.session, m.refresh)
+		},
+		shown: func(m model) bool { return m.spectator }},
+	{key: "o", id: "setBot",
+		value:  func(m model) string { return m.botStrategy() },
+		change: func(m *model) { m.difficulty = nextOf(difficulties, m.botStrategy()) },
+		shown:  func(m model) bool { return cfg.Bot != "" && !cfg.Ladder && !m.spectator }},
+}
+
+// shownSettings are the rows of the settings view for the session.
+func (m model) shownSettings() []setting {
+	var shown []setting
+	for _, st := range settings {
+		if st.shown == nil || st.shown(m) {
+			shown = append(shown, st)
+		}
+	}
+	return shown
+}
+
+// updateSettings handles a key press in the settings view: up and down pick
+// a row and enter or space change it, or the key of a row changes it right
+// away.
+func (m model) updateSettings(key string) (model, tea.Cmd) {
+	m.notice = ""
+	rows := m.shownSettings()
+	switch key {
+	case "esc":
+		m.openMenu()
+		return m, nil
+	case "up":
+		m.setting = (m.setting + len(rows) - 1) % len(rows)
+		return m, nil
+	case "down":
+		m.setting = (m.setting + 1) % len(rows)
+		return m, nil
+	case "enter", " ", "right":
+		if m.setting < len(rows) {
+			rows[m.setting].change(&m)
+		}
+		return m, nil
+	case "s":
+		if savedPrefs == nil {
+			m.notice = m.tr("prefsOff")
+			return m, nil
+		}
+		if err := savedPrefs.Put(m.identity, m.prefs()); err != nil {
+			log.Error("Could not save preferences", "path", savedPrefs.path, "error", err)
+			m.notice = m.tr("prefsFailed")
+			return m, nil
+		}
+		m.notice = m.tr("prefsSaved")
+		return m, nil
+	}
+	for i, st := range rows {
+		if st.key == key {
+			st.change(&m)
+			m.setting = i
+		}
+	}
+	return m, nil
+}
+
+func (m model) settingsView() string {
+	p := m.me()
+	lines := []string{m.tr("settings"), ""}
+	for i, st := range m.shownSettings() {
+		mark := "  "
+		if i == m.setting {
+			mark = "› "
+			if p.ascii {
+				mark = "> "
+			}
+		}
+		lines = append(lines, mark+fmt.Sprintf(m.tr(st.id), st.value(m)))
+	}
+	lines = append(lines,
+		"",
+		p.faint().Render(m.tr("settingsHelp")),
+	)
+	if m.notice != "" {
+		lines = append(lines, m.notice)
+	}
+	v := ""
+	for i, l := range lines {
+		if i > 0 {
+			v += "\n"
+		}
+		v += truncate(l, p.width)
+	}
+	return v
+}
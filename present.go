@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// nameRows is the most lines a name wraps to in the header of the
+// presentation view.
+const nameRows = 2
+
+// bigBoard reports whether the session shows the presentation view, which
+// spectators do unless they turn it off. Players keep the interactive board,
+// its cells labeled with their keys and their cursor drawn.
+func (m model) bigBoard() bool {
+	return m.presentation && m.spectator
+}
+
+// presentationView renders the board with oversized cells scaled to the
+// session's terminal, for spectators and streaming.
+func (m model) presentationView() string {
+	width, height := m.me().width, m.me().height
+	if width <= 0 || height <= 0 {
+		width, height = castWidth, castHeight
+	}
+
+	// leave room for the header lines, the status line, a blank and a
+	// spare one, every cell box has a border on each side
+	cellH := (height-nameRows-3)/3 - 2
+	if cellH < 1 {
+		cellH = 1
+	}
+	cellW := width/3 - 2
+	// keep cells roughly square, terminal cells are about twice as tall as wide
+	if cellW > 2*cellH+1 {
+		cellW = 2*cellH + 1
+	} else if cellH > cellW/2 {
+		cellH = cellW / 2
+		if cellH < 1 {
+			cellH = 1
+		}
+	}
+	if cellW < 1 {
+		cellW = 1
+	}
+	gridW := 3 * (cellW + 2)
+
+	base := m.me().text().Copy()
+	cell := base.Copy().
+		Width(cellW).
+		Height(cellH).
+		Align(lipgloss.Center, lipgloss.Center).
+		Border(lipgloss.RoundedBorder())
+	rows := make([]string, 0, len(m.board))
+	for _, row := range m.board {
+		cells := make([]string, 0, len(row))
+		for _, c := range row {
+			cells = append(cells, cell.Render(string(m.me().glyph(c))))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+	grid := lipgloss.JoinVertical(lipgloss.Left, rows...)
+
+	half := gridW / 2
+	header := lipgloss.JoinHorizontal(lipgloss.Top,
+		m.presentationName(0, half),
+		m.presentationName(1, gridW-half),
+	)
+
+	status := fmt.Sprintf(m.tr("toMove"), m.me().glyph(m.currentPlayer))
+	if m.gameOver {
+		status = m.result()
+	} else if m.waiting() {
+		status = m.readyLine()
+	}
+	status = base.Copy().Bold(true).Width(gridW).Align(lipgloss.Center).Render(truncate(status, gridW))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, status, "", grid)
+}
+
+// presentationName draws the header of the player in seat, width cells
+// wide: the piece and the score on the outer edge, so that both scores line
+// up with the grid, and the name beside them wrapped to at most nameRows
+// lines. A blank on the inner edge keeps the names of both players apart.
+func (m model) presentationName(seat, width int) string {
+	p := m.players[seat]
+	tag := fmt.Sprintf("%c %d ", m.me().glyph(1), p.score)
+	align := lipgloss.Left
+	if seat == 1 {
+		tag = fmt.Sprintf(" %d %c", p.score, m.me().glyph(-1))
+		align = lipgloss.Right
+	}
+	nameW := width - lipgloss.Width(tag) - 1
+	if nameW < 1 {
+		return truncate(tag, width)
+	}
+	name := m.me().text().Copy().Width(nameW).Align(align).Render(wrapName(p.name, nameW))
+	tag = m.me().text().Render(tag)
+	if seat == 1 {
+		return lipgloss.JoinHorizontal(lipgloss.Top, " ", name, tag)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tag, name, " ")
+}
+
+// wrapName wraps name at its words into lines of width cells, breaking
+// words longer than that. Past nameRows lines the rest is cut off with an
+// ellipsis.
+func wrapName(name string, width int) string {
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(name), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	if len(lines) > nameRows {
+		rest := strings.Join(lines[nameRows-1:], " ")
+		lines = append(lines[:nameRows-1], truncate(rest, width))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// minSize is the smallest terminal the current view of the board fits in.
+// The name and turn lines are truncated to fit, so only the grid and the
+// number of lines above it count.
+func (m model) minSize() (int, int) {
+	style := m.me().gridName()
+	w, h := m.board.gridSize(style)
+	switch {
+	case m.view == 3:
+		w, h = m.puzzle.Board.gridSize(style)
+		return w, h + 1
+	case m.bigBoard():
+		// cells of a single character in a border on each side
+		return 3 * len(m.board[0]), 3*len(m.board) + 4
+	case m.compact:
+		return w, h + 1
+	}
+	return w, h + 3
+}
+
+// tooSmall returns a request to enlarge the terminal instead of the board
+// view if it doesn't fit, as the grid would wrap into garbage.
+func (m model) tooSmall() (string, bool) {
+	p := m.me()
+	if (m.view != 1 && m.view != 3) || p.width <= 0 || p.height <= 0 {
+		return "", false
+	}
+	w, h := m.minSize()
+	if p.width >= w && p.height >= h {
+		return "", false
+	}
+	return plainRenderer.NewStyle().Width(p.width).Render(fmt.Sprintf(m.tr("tooSmall"), w, h)), true
+}
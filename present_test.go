@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestPresentationWrapsLongNames(t *testing.T) {
+	g := newTestGame(t)
+	cfg.MaxName = 40
+	cfg.Spectators = true
+	alice := g.connect("Alexandria Konstantinopolous")
+	bob := g.connect("Bartholomew Maximilian")
+	carol := g.connect("carol")
+	play(alice, bob, "q", "s")
+	carol.update(tea.WindowSizeMsg{Width: 40, Height: 24})
+	v := carol.view()
+	golden(t, "presentation", v)
+	lines := strings.Split(v, "\n")
+	for i, l := range lines {
+		if w := lipgloss.Width(l); w > 40 {
+			t.Errorf("line %d is %d wide, more than the 40 columns:\n%s", i+1, w, v)
+		}
+	}
+	// both scores on the first line, on the outer edges of the grid
+	gridW := lipgloss.Width(lines[len(lines)-1])
+	x, o := string(carol.m.me().glyph(1)), string(carol.m.me().glyph(-1))
+	if head := lines[0]; !strings.HasPrefix(head, x+" 0 ") || !strings.HasSuffix(head, " 0 "+o) || lipgloss.Width(head) != gridW {
+		t.Errorf("the scores don't line up with the grid, %d wide:\n%s", gridW, v)
+	}
+}
+
+func TestPresentationForSpectators(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Spectators = true
+	alice := g.connect("alice")
+	g.connect("bob")
+	carol := g.connect("carol")
+	if !carol.m.bigBoard() {
+		t.Errorf("the spectator doesn't start on the presentation view")
+	}
+	carol.press("p")
+	if carol.m.bigBoard() {
+		t.Errorf("p doesn't turn the presentation view off for the spectator")
+	}
+	if alice.m.bigBoard() {
+		t.Errorf("the player starts on the presentation view")
+	}
+	alice.press("p")
+	if alice.m.bigBoard() || strings.Contains(alice.view(), "╭") {
+		t.Errorf("p turns the presentation view on for the player:\n%s", alice.view())
+	}
+	alice.press("2", "4")
+	if strings.Contains(alice.view(), "presentation") {
+		t.Errorf("the player's settings offer the presentation view:\n%s", alice.view())
+	}
+}
+
+func TestWrapName(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		width int
+		want  string
+	}{
+		{"alice", 10, "alice"},
+		{"Alexandria Konstantinopolous", 12, "Alexandria\nKonstantino…"},
+		{"Bartholomew Maximilian", 12, "Bartholomew\nMaximilian"},
+		{"abcdefghijklmnop", 6, "abcdef\nghijk…"},
+	} {
+		if got := wrapName(tt.name, tt.width); got != tt.want {
+			t.Errorf("wrapName(%q, %d) = %q, want %q", tt.name, tt.width, got, tt.want)
+		}
+	}
+}
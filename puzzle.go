@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// puzzle is a position where player has a move winning the game.
+type puzzle struct {
+	Board  board `json:"board"`
+	Player int   `json:"player"`
+}
+
+// puzzles are loaded once at startup from the -puzzles file.
+var puzzles []puzzle
+
+// loadPuzzles reads a JSON list of puzzles from path and checks that each of
+// them can be won in one move.
+func loadPuzzles(path string) ([]puzzle, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ps []puzzle
+	if err := json.Unmarshal(b, &ps); err != nil {
+		return nil, err
+	}
+	if len(ps) == 0 {
+		return nil, errors.New("no puzzles")
+	}
+	for i, p := range ps {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("puzzle %d: %w", i+1, err)
+		}
+	}
+	return ps, nil
+}
+
+func (p puzzle) validate() error {
+	if p.Player != 1 && p.Player != -1 {
+		return errors.New("player must be 1 or -1")
+	}
+	if len(p.Board) != 3 {
+		return errors.New("board must have 3 rows")
+	}
+	for _, row := range p.Board {
+		if len(row) != 3 {
+			return errors.New("board rows must have 3 cells")
+		}
+		for _, c := range row {
+			if c < -1 || c > 1 {
+				return errors.New("cells must be 1, -1 or 0")
+			}
+		}
+	}
+	if winner(p.Board) != 0 {
+		return errors.New("board is already won")
+	}
+	for x := range p.Board {
+		for y := range p.Board[x] {
+			if p.solvedBy(x, y) {
+				return nil
+			}
+		}
+	}
+	return errors.New("no winning move")
+}
+
+// solvedBy reports whether playing x, y wins the puzzle.
+func (p puzzle) solvedBy(x int, y int) bool {
+	if p.Board[x][y] != 0 {
+		return false
+	}
+	b := p.Board.clone()
+	b[x][y] = p.Player
+	return winner(b) == p.Player
+}
+
+// puzzleOfTheDay picks the puzzle for the day of t, cycling through all of
+// them one day at a time.
+func puzzleOfTheDay(t time.Time) puzzle {
+	year, month, day := t.UTC().Date()
+	days := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+	return puzzles[int(days%int64(len(puzzles)))]
+}
+
+// startPuzzle puts the puzzle of the day on the session's puzzle board.
+func (m *model) startPuzzle() {
+	m.puzzle = puzzleOfTheDay(time.Now())
+	m.puzzle.Board = m.puzzle.Board.clone()
+	m.puzzleSolved = false
+	m.view = 3
+}
+
+// updatePuzzle handles a key press in the puzzle view.
+func (m model) updatePuzzle(key string) model {
+	switch key {
+	case "esc":
+		m.view = 1
+		return m
+	}
+	cell, ok := cellKeys[key]
+	if !ok || m.puzzleSolved {
+		return m
+	}
+	if m.puzzle.solvedBy(cell[0], cell[1]) {
+		m.puzzle.Board[cell[0]][cell[1]] = m.puzzle.Player
+		m.puzzleSolved = true
+		m.notice = m.tr("puzzleSolved")
+	} else {
+		m.notice = m.tr("puzzleWrong")
+	}
+	return m
+}
+
+func (m model) puzzleView() string {
+	width := m.me().width
+	v := truncate(fmt.Sprintf(m.tr("puzzlePrompt"), m.me().glyph(m.puzzle.Player)), width) + "\n" + m.me().grid(m.puzzle.Board)
+	if m.notice != "" {
+		v += "\n" + truncate(m.notice, width)
+	}
+	return v
+}
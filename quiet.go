@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// quietHours is the daily window of -quiethours, as times of day in
+// quietZone. It may wrap around midnight, like 22:00-07:00.
+var (
+	quietHours [2]time.Duration
+	quietZone  = time.Local
+)
+
+// parseQuietHours parses a window like "22:00-07:00" into the times of day
+// it starts and ends at.
+func parseQuietHours(s string) ([2]time.Duration, error) {
+	var w [2]time.Duration
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return w, fmt.Errorf("quiet hours %q are not like 22:00-07:00", s)
+	}
+	for i, v := range []string{from, to} {
+		t, err := time.Parse("15:04", strings.TrimSpace(v))
+		if err != nil {
+			return w, fmt.Errorf("quiet hours %q: %w", s, err)
+		}
+		w[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if w[0] == w[1] {
+		return w, fmt.Errorf("quiet hours %q are empty", s)
+	}
+	return w, nil
+}
+
+// quietUntil reports whether t is in the quiet hours, and when they end.
+func quietUntil(t time.Time) (time.Time, bool) {
+	if cfg.QuietHours == "" {
+		return time.Time{}, false
+	}
+	t = t.In(quietZone)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, quietZone)
+	since := t.Sub(midnight)
+	from, to := quietHours[0], quietHours[1]
+	switch {
+	case from < to && since >= from && since < to, from > to && since < to:
+		return midnight.Add(to), true
+	case from > to && since >= from:
+		return midnight.AddDate(0, 0, 1).Add(to), true
+	}
+	return time.Time{}, false
+}
+
+// Playing reports whether a game is in progress, which quiet hours let
+// finish.
+func (gs *gameState) Playing() bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return len(gs.m.record.moves) > 0 && !gs.m.gameOver
+}
+
+// quietBlocks reports whether quiet hours keep a new game from starting,
+// telling the session when they end.
+func (m *model) quietBlocks() bool {
+	until, ok := quietUntil(time.Now())
+	if !ok {
+		return false
+	}
+	m.notice = fmt.Sprintf(m.tr("quietHours"), until.Format("15:04"))
+	m.resetIn = 0
+	return true
+}
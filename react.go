@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reactions are the glyphs spectators react with, by key.
+var reactions = map[string]rune{"!": '★', "+": '♥', "~": '☺'}
+
+const (
+	reactionTime     = 3 * time.Second // how long a reaction floats
+	reactionCooldown = time.Second     // between two reactions of a spectator
+	reactionWidth    = 20              // columns the reactions float in
+)
+
+// reactionMsg tells the sessions that a spectator reacted.
+type reactionMsg struct {
+	glyph rune
+}
+
+// reaction floats in the view of a session until it runs out of ticks.
+type reaction struct {
+	glyph rune
+	col   int
+	left  int
+}
+
+// react sends the reaction of key to every session, unless the spectator
+// just reacted.
+func (m *model) react(key string) tea.Cmd {
+	if m.reactWait > 0 {
+		return nil
+	}
+	m.reactWait = ticks(reactionCooldown)
+	return m.gs.broadcast(reactionMsg{glyph: reactions[key]})
+}
+
+// addReaction floats the reaction of msg, spreading reactions out over the
+// columns as they come in.
+func (m *model) addReaction(msg reactionMsg) {
+	m.reacted++
+	m.reactions = append(m.reactions, reaction{
+		glyph: msg.glyph,
+		col:   m.reacted * 7 % reactionWidth,
+		left:  ticks(reactionTime),
+	})
+}
+
+// tickReactions lets the reactions run out and the cooldown pass.
+func (m *model) tickReactions() {
+	if m.reactWait > 0 {
+		m.reactWait--
+	}
+	kept := m.reactions[:0]
+	for _, r := range m.reactions {
+		if r.left--; r.left > 0 {
+			kept = append(kept, r)
+		}
+	}
+	m.reactions = kept
+}
+
+// reactionLine draws the floating reactions for spectators, and players who
+// opted in with the settings. Other players only see how many there are, so
+// as not to be distracted. Until someone reacts spectators see the keys.
+func (m model) reactionLine(width int) string {
+	if len(m.reactions) == 0 {
+		if m.spectator {
+			return m.me().faint().Render(truncate(m.tr("reactHelp"), width))
+		}
+		return ""
+	}
+	if !m.spectator && !m.showReactions {
+		return truncate(fmt.Sprintf(m.tr("reactions"), len(m.reactions)), width)
+	}
+	line := []rune(strings.Repeat(" ", reactionWidth))
+	for _, r := range m.reactions {
+		line[r.col] = r.glyph
+	}
+	return truncate(strings.TrimRight(string(line), " "), width)
+}
@@ -0,0 +1,43 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// readyMsg carries the readiness of both seats after it changed.
+type readyMsg [2]bool
+
+// SetReady marks the seat as ready for the current game and returns a
+// command broadcasting the change.
+func (gs *gameState) SetReady(seat int) tea.Cmd {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.ready[seat] = true
+	return gs.broadcast(readyMsg(gs.ready))
+}
+
+// ClearReady makes both seats ready up again for a new game.
+func (gs *gameState) ClearReady() tea.Cmd {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.ready = [2]bool{}
+	// the bot is always ready
+	if cfg.Bot != "" {
+		gs.ready[botSeat] = true
+	}
+	return gs.broadcast(readyMsg(gs.ready))
+}
+
+// waiting reports whether the game is held until both players are ready.
+func (m model) waiting() bool {
+	return cfg.ReadyCheck && !m.gameOver && !(m.ready[0] && m.ready[1])
+}
+
+// readyLine asks the player to ready up, or to wait for the opponent.
+func (m model) readyLine() string {
+	if m.spectator {
+		return m.tr("readyPlayers")
+	}
+	if m.ready[m.self] {
+		return m.tr("readyWait")
+	}
+	return m.tr("readyUp")
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recap sums up a finished game for the recap screen, view 5, which can
+// step through it move by move.
+type recap struct {
+	id     string
+	record recording
+	names  [2]string
+	winner int
+	moves  int
+	took   time.Duration
+}
+
+// newRecap sums up the game of m, which just finished.
+func newRecap(m model) *recap {
+	r := &recap{
+		id:     m.record.id,
+		record: m.record.clone(),
+		names:  [2]string{m.players[0].name, m.players[1].name},
+		winner: m.winner,
+		moves:  len(m.record.moves),
+	}
+	if r.moves > 0 {
+		r.took = m.record.moves[r.moves-1].at.Sub(m.record.started)
+	}
+	return r
+}
+
+// LastGame returns the recap of the last finished game, or nil before the
+// first one finishes.
+func (gs *gameState) LastGame() *recap {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.last
+}
+
+// boardAt replays the first n moves of the game on an empty board.
+func (r recap) boardAt(n int) board {
+	g := model{board: newBoard(), currentPlayer: r.record.first}
+	for _, mv := range r.record.moves[:n] {
+		updateCell(&g, mv.x, mv.y)
+	}
+	return g.board
+}
+
+// openRecap shows the last finished game, from its final position.
+func (m *model) openRecap() {
+	m.recap = m.gs.LastGame()
+	m.scrub = 0
+	if m.recap != nil {
+		m.scrub = m.recap.moves
+	}
+	m.jump = ""
+	m.view = 5
+}
+
+// updateRecap steps through the recapped game with the arrow keys, or jumps
+// to the move number typed and confirmed with enter.
+func (m model) updateRecap(key string) (tea.Model, tea.Cmd) {
+	m.notice = ""
+	if m.recap == nil {
+		if key == "esc" || key == "g" {
+			m.view = 1
+		}
+		return m, nil
+	}
+	switch key {
+	case "esc", "g":
+		m.view = 1
+	case "left", ",":
+		if m.scrub > 0 {
+			m.scrub--
+		}
+	case "right", ".":
+		if m.scrub < m.recap.moves {
+			m.scrub++
+		}
+	case "home":
+		m.scrub = 0
+	case "end":
+		m.scrub = m.recap.moves
+	case "backspace":
+		if m.jump != "" {
+			m.jump = m.jump[:len(m.jump)-1]
+		}
+	case "enter":
+		n, err := strconv.Atoi(m.jump)
+		m.jump = ""
+		if err != nil || n < 0 || n > m.recap.moves {
+			m.notice = fmt.Sprintf(m.tr("recapRange"), m.recap.moves)
+			return m, nil
+		}
+		m.scrub = n
+	default:
+		if len(key) == 1 && key[0] >= '0' && key[0] <= '9' && len(m.jump) < 3 {
+			m.jump += key
+		}
+	}
+	return m, nil
+}
+
+// progress draws a bar of width filled for done out of total.
+func (p player) progress(done, total, width int) string {
+	full, empty := "█", "░"
+	if p.ascii {
+		full, empty = "#", "-"
+	}
+	n := width
+	if total > 0 {
+		n = done * width / total
+	}
+	return strings.Repeat(full, n) + strings.Repeat(empty, width-n)
+}
+
+// recapWidth is the widest the progress bar of the recap screen gets.
+const recapWidth = 18
+
+// recapView shows the last finished game to the session at the move it
+// stepped to.
+func (m model) recapView() string {
+	p := m.me()
+	help := p.faint().Render(truncate(m.tr("recapHelp"), p.width))
+	r := m.recap
+	if r == nil {
+		return truncate(m.tr("recapNone"), p.width) + "\n\n" + help
+	}
+	result := m.tr("draw")
+	if r.winner != 0 {
+		result = fmt.Sprintf(m.tr("wins"), r.names[seat(r.winner)])
+	}
+	took := r.took.Round(time.Second)
+	lines := []string{
+		p.text().Render(truncate(fmt.Sprintf(m.tr("recapTitle"), r.id), p.width)),
+		p.grid(r.boardAt(m.scrub)),
+		truncate(result, p.width),
+		truncate(fmt.Sprintf(m.tr("recapMoves"), r.moves, took), p.width),
+		truncate(fmt.Sprintf(m.tr("recapAt"), m.scrub, r.moves), p.width),
+	}
+	bar := recapWidth
+	if p.width > 0 && p.width < bar {
+		bar = p.width
+	}
+	lines = append(lines, p.progress(m.scrub, r.moves, bar))
+	if m.jump != "" {
+		lines = append(lines, truncate(fmt.Sprintf(m.tr("recapJump"), m.jump), p.width))
+	}
+	if m.notice != "" {
+		lines = append(lines, truncate(m.notice, p.width))
+	}
+	lines = append(lines, "", help)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// With -redrawfilter, redraws skip the spectators off the game screen, on
+// the name, the menu, a puzzle, the settings or the recap, as nothing they
+// see changes. They catch up on the game when they return to it. Every other
+// message, and every redraw when in doubt, still goes to all sessions:
+// the players always get them, their timers and the bot run on the game.
+
+// offGame reports whether the session is a spectator on a screen without
+// the live game, so that it can do without its redraws.
+func (m model) offGame() bool {
+	return cfg.RedrawFilter && m.spectator && m.view != 1
+}
+
+// trackGame tells gs when the session, which was off the game screen or
+// not as told by was, changed screens. next is the model Update returns,
+// synced with the game if the session returns to it.
+func (m model) trackGame(was bool, next *tea.Model) {
+	n, ok := (*next).(model)
+	if !ok || n.offGame() == was {
+		return
+	}
+	n.gs.SetOffGame(n.session, &n)
+	*next = n
+}
+
+// SetOffGame stores whether the session m of id is off the game screen,
+// syncing m with the game when it returns.
+func (gs *gameState) SetOffGame(id string, m *model) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	sess, ok := gs.sessions[id]
+	if !ok {
+		return
+	}
+	sess.offGame = m.offGame()
+	gs.sessions[id] = sess
+	if !sess.offGame {
+		gs.syncLocked(m)
+	}
+}
+
+// redrawLocked broadcasts a redraw to the sessions showing the game. The
+// redraws of a spectator who set a refresh throttle are deferred until it
+// has passed since their last one, and those deferred in the meantime are
+// sent as one, see releaseRedraw. The caller must hold gs.mu.
+func (gs *gameState) redrawLocked() {
+	msg := redraw()
+	now := time.Now()
+	for id, sess := range gs.sessions {
+		if sess.offGame {
+			continue
+		}
+		if sess.throttle > 0 && gs.seats[0] != id && gs.seats[1] != id {
+			if wait := sess.throttle - now.Sub(sess.redrawn); wait > 0 {
+				if !sess.deferred {
+					sess.deferred = true
+					id := id
+					time.AfterFunc(wait, func() { gs.releaseRedraw(id) })
+				}
+				gs.sessions[id] = sess
+				continue
+			}
+			sess.redrawn = now
+			gs.sessions[id] = sess
+		}
+		sess.box.post(msg)
+	}
+}
+
+// releaseRedraw sends the session id the redraw deferred by its throttle.
+func (gs *gameState) releaseRedraw(id string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	sess, ok := gs.sessions[id]
+	if !ok || !sess.deferred {
+		return
+	}
+	sess.deferred = false
+	sess.redrawn = time.Now()
+	gs.sessions[id] = sess
+	sess.box.post(redraw())
+}
+
+// refreshChoices are the throttles a spectator can set on their redraws, 0
+// for every redraw as it happens.
+var refreshChoices = []duration{0, duration(time.Second), duration(2 * time.Second), duration(5 * time.Second)}
+
+// SetThrottle sets the least time between the redraws of the session id,
+// see refreshChoices. It only defers redraws while id spectates.
+func (gs *gameState) SetThrottle(id string, d duration) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if sess, ok := gs.sessions[id]; ok {
+		sess.throttle = time.Duration(d)
+		gs.sessions[id] = sess
+	}
+}
+
+// nextRefresh returns the throttle after d in refreshChoices.
+func nextRefresh(d duration) duration {
+	for i, c := range refreshChoices {
+		if c == d {
+			return refreshChoices[(i+1)%len(refreshChoices)]
+		}
+	}
+	return refreshChoices[0]
+}
+
+// refreshName shows the throttle d in the settings view.
+func (m model) refreshName(d duration) string {
+	if d == 0 {
+		return m.tr("refreshLive")
+	}
+	return d.String()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOffGame(t *testing.T) {
+	for _, tt := range []struct {
+		filter    bool
+		spectator bool
+		view      int
+		want      bool
+	}{
+		{true, true, 0, true},
+		{true, true, 1, false},
+		{true, true, 2, true},
+		{true, true, 3, true},
+		{true, true, 4, true},
+		{true, true, 5, true},
+		// players always get their redraws
+		{true, false, 1, false},
+		{true, false, 2, false},
+		{true, false, 4, false},
+		{false, true, 1, false},
+		{false, true, 2, false},
+	} {
+		t.Run(fmt.Sprintf("filter %v spectator %v view %d", tt.filter, tt.spectator, tt.view), func(t *testing.T) {
+			g := newTestGame(t)
+			cfg.RedrawFilter = tt.filter
+			m := g.gs.m
+			m.spectator, m.view = tt.spectator, tt.view
+			if got := m.offGame(); got != tt.want {
+				t.Errorf("offGame is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// redraws waits for the messages gs sent to s and returns how many were
+// redraws.
+func redraws(s *testSession) int {
+	n := 0
+	for {
+		select {
+		case msg := <-s.inbox:
+			if _, ok := msg.(redrawMsg); ok {
+				n++
+			}
+		case <-time.After(20 * time.Millisecond):
+			return n
+		}
+	}
+}
+
+func TestRedrawSkipsOffGame(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Spectators = true
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	carol := g.connect("carol")
+	dave := g.connect("dave")
+	// alice, playing, and carol, spectating, go to the menu
+	alice.press("2")
+	carol.press("2")
+	if alice.m.view != 2 || carol.m.view != 2 {
+		t.Fatalf("alice is at view %d and carol at %d, want both in the menu", alice.m.view, carol.m.view)
+	}
+	for i := 0; i < 3; i++ {
+		g.gs.mu.Lock()
+		g.gs.redrawLocked()
+		g.gs.mu.Unlock()
+		for _, s := range []*testSession{alice, bob, dave} {
+			if n := redraws(s); n != 1 {
+				t.Errorf("redraw %d: %s got %d redraws, want 1", i+1, s.m.me().name, n)
+			}
+		}
+		if n := redraws(carol); n != 0 {
+			t.Errorf("redraw %d: carol got %d redraws in the menu, want none", i+1, n)
+		}
+	}
+	// back on the board carol catches up on the game she missed
+	alice.press("1")
+	play(alice, bob, "q", "w")
+	carol.press("1")
+	if carol.m.view != 1 {
+		t.Fatalf("carol is at view %d, want the board", carol.m.view)
+	}
+	if carol.m.board[0][0] != 1 || carol.m.board[0][1] != -1 {
+		t.Errorf("carol didn't catch up on the game:\n%s", carol.view())
+	}
+}
+
+func TestRedrawThrottle(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Spectators = true
+	g.connect("alice")
+	g.connect("bob")
+	carol := g.connect("carol")
+	throttle := 300 * time.Millisecond
+	g.gs.SetThrottle(carol.id, duration(throttle))
+	burst := func() {
+		for i := 0; i < 10; i++ {
+			g.gs.mu.Lock()
+			g.gs.redrawLocked()
+			g.gs.mu.Unlock()
+		}
+	}
+	start := time.Now()
+	burst()
+	// the first redraw goes out, the rest wait for the throttle as one
+	if n := redraws(carol); n != 1 {
+		t.Fatalf("carol got %d redraws of the burst at once, want 1", n)
+	}
+	burst()
+	time.Sleep(time.Until(start.Add(throttle - 80*time.Millisecond)))
+	if n := redraws(carol); n != 0 {
+		t.Fatalf("carol got %d redraws before the throttle passed, want none", n)
+	}
+	time.Sleep(time.Until(start.Add(throttle + 50*time.Millisecond)))
+	if n := redraws(carol); n != 1 {
+		t.Errorf("carol got %d redraws once the throttle passed, want the deferred one", n)
+	}
+	time.Sleep(throttle)
+	if n := redraws(carol); n != 0 {
+		t.Errorf("carol got %d more redraws after the burst, want none", n)
+	}
+}
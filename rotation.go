@@ -0,0 +1,53 @@
+package main
+
+import "fmt"
+
+// series follows the games played in a row by the same two players, for
+// -rotation to pick who starts the next one.
+type series struct {
+	games int // finished since both players sat down
+	next  int // piece starting the next game, 0 to leave it be
+}
+
+// nextFirst returns the piece starting the game after one started by first
+// and won by the piece winner, or drawn, under rotation: the "winner" or
+// the "loser" starts, or the starts "alternate". Drawn games alternate
+// either way. It returns 0 for rotations leaving it to the coin flip or to
+// the turn order.
+func nextFirst(rotation string, first, winner int) int {
+	if first == 0 {
+		first = 1
+	}
+	switch {
+	case rotation == "winner" && winner != 0:
+		return winner
+	case rotation == "loser" && winner != 0:
+		return -winner
+	case rotation == "winner", rotation == "loser", rotation == "alternate":
+		return -first
+	}
+	return 0
+}
+
+// endSeriesLocked starts a new series once a player leaves their seat. The
+// caller must hold gs.mu.
+func (gs *gameState) endSeriesLocked() {
+	gs.series = series{}
+}
+
+// NextFirst returns the piece starting the next game of the series, or 0
+// to leave it be.
+func (gs *gameState) NextFirst() int {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.series.next
+}
+
+// nextFirstLine tells who starts the next game, on the game over screen.
+func (m model) nextFirstLine() (string, bool) {
+	first := nextFirst(cfg.Rotation, m.record.first, m.winner)
+	if first == 0 {
+		return "", false
+	}
+	return fmt.Sprintf(m.tr("nextFirst"), m.players[seat(first)].name), true
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// games are the moves of a game won by whoever starts it, won by the other
+// player or drawn.
+var (
+	firstWins  = []string{"q", "a", "w", "s", "e"}
+	secondWins = []string{"a", "q", "s", "w", "c", "e"}
+	drawn      = []string{"q", "w", "e", "s", "a", "d", "x", "z", "c"}
+)
+
+func TestRotation(t *testing.T) {
+	// X wins, O wins, the game is drawn, X wins
+	outcomes := []int{1, -1, 0, 1}
+	for _, tt := range []struct {
+		rotation string
+		starts   []int // the piece starting each game
+	}{
+		{"winner", []int{1, 1, -1, 1, 1}},
+		{"loser", []int{1, -1, 1, -1, -1}},
+		{"alternate", []int{1, -1, 1, -1, 1}},
+	} {
+		t.Run(tt.rotation, func(t *testing.T) {
+			g := newTestGame(t)
+			cfg.Rotation = tt.rotation
+			alice := g.connect("alice")
+			g.connect("bob")
+			for i, winner := range outcomes {
+				first := g.gs.m.record.first
+				if first != tt.starts[i] {
+					t.Fatalf("game %d starts with %d, want %d", i+1, first, tt.starts[i])
+				}
+				moves := drawn
+				switch winner {
+				case first:
+					moves = firstWins
+				case -first:
+					moves = secondWins
+				}
+				alice.press(moves...)
+				if !alice.m.gameOver || alice.m.winner != winner {
+					t.Fatalf("game %d ended %v with winner %d, want %d", i+1, alice.m.gameOver, alice.m.winner, winner)
+				}
+				next := alice.m.players[seat(tt.starts[i+1])].name
+				if want := next + " starts the next game"; !strings.Contains(alice.view(), want) {
+					t.Errorf("game %d: the game over screen doesn't say %q:\n%s", i+1, want, alice.view())
+				}
+				alice.press("esc")
+			}
+			if first := g.gs.m.record.first; first != tt.starts[len(outcomes)] {
+				t.Errorf("the last game starts with %d, want %d", first, tt.starts[len(outcomes)])
+			}
+		})
+	}
+}
+
+func TestRotationRandom(t *testing.T) {
+	g := newTestGame(t)
+	cfg.Rotation = "random"
+	// as loadConfig does
+	cfg.RandomStart = true
+	cfg.Animations = false
+	alice := g.connect("alice")
+	g.connect("bob")
+	for i := 0; i < 4; i++ {
+		first := g.gs.m.record.first
+		moves := secondWins
+		if i%2 == 0 {
+			moves = firstWins
+		}
+		alice.press(moves...)
+		if !alice.m.gameOver {
+			t.Fatalf("game %d isn't over:\n%s", i+1, alice.view())
+		}
+		if next := g.gs.NextFirst(); next != 0 {
+			t.Errorf("game %d: the series picks %d to start, want it left to the coin flip", i+1, next)
+		}
+		if strings.Contains(alice.view(), "starts the next game") {
+			t.Errorf("game %d: the game over screen names who starts a coin flip:\n%s", i+1, alice.view())
+		}
+		if first != 1 && first != -1 {
+			t.Errorf("game %d starts with %d", i+1, first)
+		}
+		alice.press("esc")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// A busy server connects and plays more than anyone reads in its log, so
+// the connection and move events can be sampled, see -logsample. Errors,
+// warnings and game results are logged as they are and never sampled.
+
+// sampler keeps 1 in every cfg.LogSample of its events.
+type sampler struct {
+	n uint64
+}
+
+var (
+	connSample sampler // connections, with their connect and close lines
+	moveSample sampler // moves timing out
+)
+
+// keep reports whether the next event is logged. The first event always is.
+func (s *sampler) keep() bool {
+	if cfg.LogSample <= 1 {
+		return true
+	}
+	return (atomic.AddUint64(&s.n, 1)-1)%uint64(cfg.LogSample) == 0
+}
+
+// sampledKey holds in a session's context whether its connection is logged.
+type sampledKey struct{}
+
+// sampleMiddleware decides once per connection whether it is logged, by
+// logged, the connection log middleware, as well as by the lines of
+// connSampled.
+func sampleMiddleware(logged wish.Middleware) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		withLog := logged(next)
+		return func(s ssh.Session) {
+			keep := connSample.keep()
+			s.Context().SetValue(sampledKey{}, keep)
+			if keep {
+				withLog(s)
+				return
+			}
+			next(s)
+		}
+	}
+}
+
+// connSampled reports whether the connection of s is logged.
+func connSampled(s ssh.Session) bool {
+	keep, ok := s.Context().Value(sampledKey{}).(bool)
+	return keep || !ok
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// playerScore is the record of an identity over all the games it finished.
+type playerScore struct {
+	ID     string `json:"-"`
+	Name   string `json:"name"` // the name it last played under
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+	// WinsBy counts the wins by how they were won
+	WinsBy map[winReason]int `json:"winsBy,omitempty"`
+}
+
+// copied returns ps with a copy of WinsBy, for callers outside the lock of
+// the store keeping ps.
+func (ps playerScore) copied() playerScore {
+	if ps.WinsBy != nil {
+		by := make(map[winReason]int, len(ps.WinsBy))
+		for r, n := range ps.WinsBy {
+			by[r] = n
+		}
+		ps.WinsBy = by
+	}
+	return ps
+}
+
+// Store keeps the records of the players between games, see -scorestore.
+type Store interface {
+	// RecordResult adds a finished game between the identities ids,
+	// playing under names, won by the one at index winner as won tells or
+	// drawn if winner is -1. Empty identities, like the bot's, aren't
+	// recorded.
+	RecordResult(ids, names [2]string, winner int, won winReason) error
+	// TopPlayers returns the n players with the most wins, ties going to
+	// the fewest losses.
+	TopPlayers(n int) []playerScore
+	// GetPlayer returns the record of the identity id.
+	GetPlayer(id string) (playerScore, bool)
+}
+
+// stores are the backends selectable with -scorestore, opening the store
+// at path.
+var stores = map[string]func(path string) (Store, error){
+	"memory": func(string) (Store, error) { return newMemStore(), nil },
+	"json":   loadFileStore,
+	"sqlite": openSQLiteStore,
+}
+
+// scores is the store of the server, set up by setupConfig.
+var scores Store = newMemStore()
+
+// memStore keeps the records in memory, for as long as the server runs.
+type memStore struct {
+	mu      sync.Mutex
+	players map[string]playerScore
+}
+
+func newMemStore() *memStore {
+	return &memStore{players: map[string]playerScore{}}
+}
+
+// RecordResult implements Store.
+func (ms *memStore) RecordResult(ids, names [2]string, winner int, won winReason) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.recordLocked(ids, names, winner, won)
+	return nil
+}
+
+func (ms *memStore) recordLocked(ids, names [2]string, winner int, won winReason) {
+	for i, id := range ids {
+		if id == "" {
+			continue
+		}
+		ps := ms.players[id]
+		ps.Name = names[i]
+		switch winner {
+		case -1:
+			ps.Draws++
+		case i:
+			ps.Wins++
+			if ps.WinsBy == nil {
+				ps.WinsBy = map[winReason]int{}
+			}
+			ps.WinsBy[won]++
+		default:
+			ps.Losses++
+		}
+		ms.players[id] = ps
+	}
+}
+
+// TopPlayers implements Store.
+func (ms *memStore) TopPlayers(n int) []playerScore {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	top := make([]playerScore, 0, len(ms.players))
+	for id, ps := range ms.players {
+		ps.ID = id
+		top = append(top, ps.copied())
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Wins != top[j].Wins {
+			return top[i].Wins > top[j].Wins
+		}
+		if top[i].Losses != top[j].Losses {
+			return top[i].Losses < top[j].Losses
+		}
+		return top[i].ID < top[j].ID
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// GetPlayer implements Store.
+func (ms *memStore) GetPlayer(id string) (playerScore, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ps, ok := ms.players[id]
+	ps.ID = id
+	return ps.copied(), ok
+}
+
+// fileStore is a memStore saved to a JSON file after every game.
+type fileStore struct {
+	*memStore
+	path string
+}
+
+// loadFileStore reads the records stored at path. A missing file starts an
+// empty store; a corrupt one is logged and replaced on the next save.
+func loadFileStore(path string) (Store, error) {
+	st := &fileStore{memStore: newMemStore(), path: path}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &st.players); err != nil {
+		log.Warn("Ignoring corrupt scores", "path", path, "error", err)
+		st.players = map[string]playerScore{}
+	}
+	return st, nil
+}
+
+// RecordResult implements Store, saving the records once they are added.
+func (st *fileStore) RecordResult(ids, names [2]string, winner int, won winReason) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.recordLocked(ids, names, winner, won)
+	b, err := json.MarshalIndent(st.players, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(st.path, b)
+}
+
+// result is a finished game as recorded in the scores.
+type result struct {
+	game   string
+	ids    [2]string
+	names  [2]string
+	winner int // index in ids, -1 for a draw
+	won    winReason
+}
+
+// newResult returns the result of the game g just finished. The identities
+// are those of the seated players, as the session finishing the game only
+// knows the names of the others.
+func newResult(seated [2]player, g model) result {
+	r := result{game: g.record.id, winner: -1, won: g.record.won}
+	for i := range seated {
+		r.ids[i], r.names[i] = seated[i].identity, g.players[i].name
+	}
+	if g.winner != 0 {
+		r.winner = seat(g.winner)
+	}
+	return r
+}
+
+// saveResults records the results of the games over in the scores. It
+// must be called without holding gs.mu: a store may write a file or a
+// database, which would stall every session waiting for the lock. The
+// sessions hear of it after, see recordedMsg.
+func (gs *gameState) saveResults() {
+	unsaved := gs.takeUnsaved()
+	for _, r := range unsaved {
+		if err := scores.RecordResult(r.ids, r.names, r.winner, r.won); err != nil {
+			log.Error("Could not record the result", "game", r.game, "error", err)
+		}
+	}
+	if len(unsaved) > 0 {
+		gs.BroadcastMessage(recordedMsg{})
+	}
+}
+
+// takeUnsaved removes the results waiting in gs.unsaved and returns them.
+func (gs *gameState) takeUnsaved() []result {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	unsaved := gs.unsaved
+	gs.unsaved = nil
+	return unsaved
+}
+
+// topPlayers lists the best players of the scores, for `ssh host top`.
+func topPlayers() string {
+	top := scores.TopPlayers(topCount)
+	if len(top) == 0 {
+		return "no games recorded yet"
+	}
+	lines := make([]string, len(top))
+	for i, ps := range top {
+		lines[i] = fmt.Sprintf("%2d. %-16s %3d won %3d lost %3d drawn", i+1, ps.Name, ps.Wins, ps.Losses, ps.Draws)
+	}
+	return cfg.ServerName + "\n" + strings.Join(lines, "\n")
+}
+
+// topCount is the number of players listed by `ssh host top`.
+const topCount = 10
+
+// scoresCSV writes the records of all players to w as CSV for `ssh host
+// csv`, best first like topPlayers, under a header row that is written
+// even when no game was recorded yet. The wins by each winReason follow
+// the totals.
+func scoresCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"identity", "name", "games", "wins", "losses", "draws", "win_rate"}
+	for _, r := range winReasons {
+		header = append(header, "wins_"+string(r))
+	}
+	cw.Write(header)
+	for _, ps := range scores.TopPlayers(math.MaxInt) {
+		games := ps.Wins + ps.Losses + ps.Draws
+		rate := 0.0
+		if games > 0 {
+			rate = float64(ps.Wins) / float64(games)
+		}
+		row := []string{
+			ps.ID,
+			ps.Name,
+			strconv.Itoa(games),
+			strconv.Itoa(ps.Wins),
+			strconv.Itoa(ps.Losses),
+			strconv.Itoa(ps.Draws),
+			strconv.FormatFloat(rate, 'f', 3, 64),
+		}
+		for _, r := range winReasons {
+			row = append(row, strconv.Itoa(ps.WinsBy[r]))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
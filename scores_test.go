@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	goreflect "reflect"
+	"testing"
+)
+
+// TestStores runs the same games through every backend of -scorestore,
+// reopening those keeping a file to check the records survive it.
+func TestStores(t *testing.T) {
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "scores")
+			st, err := open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := [2]string{"key:alice", "key:bob"}
+			for _, game := range []struct {
+				winner int
+				won    winReason
+			}{{0, winLine}, {0, winResign}, {1, winLine}, {-1, ""}} {
+				if err := st.RecordResult(ids, [2]string{"alice", "bob"}, game.winner, game.won); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// carol plays the bot, which has no identity to record
+			if err := st.RecordResult([2]string{"key:carol", ""}, [2]string{"carol", "bot"}, 1, winTimeout); err != nil {
+				t.Fatal(err)
+			}
+			// bob plays under a new name
+			if err := st.RecordResult([2]string{"key:carol", "key:bob"}, [2]string{"carol", "robert"}, 1, winDisconnect); err != nil {
+				t.Fatal(err)
+			}
+			checkStore(t, st)
+			if name == "memory" {
+				return
+			}
+			if st, err = open(path); err != nil {
+				t.Fatal(err)
+			}
+			checkStore(t, st)
+		})
+	}
+}
+
+func checkStore(t *testing.T, st Store) {
+	t.Helper()
+	// ties go to the fewest losses
+	want := []playerScore{
+		{ID: "key:alice", Name: "alice", Wins: 2, Losses: 1, Draws: 1,
+			WinsBy: map[winReason]int{winLine: 1, winResign: 1}},
+		{ID: "key:bob", Name: "robert", Wins: 2, Losses: 2, Draws: 1,
+			WinsBy: map[winReason]int{winLine: 1, winDisconnect: 1}},
+		{ID: "key:carol", Name: "carol", Wins: 0, Losses: 2},
+	}
+	top := st.TopPlayers(10)
+	if len(top) != len(want) {
+		t.Fatalf("top players are %+v, want %+v", top, want)
+	}
+	for i := range want {
+		if !goreflect.DeepEqual(top[i], want[i]) {
+			t.Errorf("top player %d is %+v, want %+v", i+1, top[i], want[i])
+		}
+	}
+	if top := st.TopPlayers(1); len(top) != 1 || !goreflect.DeepEqual(top[0], want[0]) {
+		t.Errorf("the best player is %+v, want %+v", top, want[0])
+	}
+	if ps, ok := st.GetPlayer("key:alice"); !ok || !goreflect.DeepEqual(ps, want[0]) {
+		t.Errorf("alice's record is %+v, %v, want %+v", ps, ok, want[0])
+	}
+	if ps, ok := st.GetPlayer(""); ok {
+		t.Errorf("the bot has the record %+v", ps)
+	}
+}
+
+func TestFileStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	st, err := loadFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if top := st.TopPlayers(10); len(top) != 0 {
+		t.Errorf("a corrupt file holds %+v", top)
+	}
+	if err := st.RecordResult([2]string{"key:alice", "key:bob"}, [2]string{"alice", "bob"}, 0, winLine); err != nil {
+		t.Fatal(err)
+	}
+	if st, err = loadFileStore(path); err != nil {
+		t.Fatal(err)
+	}
+	if ps, ok := st.GetPlayer("key:alice"); !ok || ps.Wins != 1 {
+		t.Errorf("the corrupt file wasn't replaced, alice's record is %+v", ps)
+	}
+}
+
+// lockedStore is a Store reporting whether gs.mu was held while it
+// recorded a result.
+type lockedStore struct {
+	*memStore
+	gs     *gameState
+	locked chan bool
+}
+
+func (st lockedStore) RecordResult(ids, names [2]string, winner int, won winReason) error {
+	locked := !st.gs.mu.TryLock()
+	if !locked {
+		st.gs.mu.Unlock()
+	}
+	st.locked <- locked
+	return st.memStore.RecordResult(ids, names, winner, won)
+}
+
+func TestResultRecordedUnlocked(t *testing.T) {
+	for name, keys := range map[string][]string{
+		"win":     {"q", "w", "a", "s", "z"},
+		"forfeit": {"q", "w", "v"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := newTestGame(t)
+			alice := g.connect("alice")
+			bob := g.connect("bob")
+			st := lockedStore{memStore: newMemStore(), gs: g.gs, locked: make(chan bool, 1)}
+			scores = st
+			play(alice, bob, keys...)
+			select {
+			case locked := <-st.locked:
+				if locked {
+					t.Error("the result was recorded holding gs.mu")
+				}
+			default:
+				t.Fatal("the result wasn't recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// free reports whether seat is free to take, which the bot's never is, nor
+// one held for a dropped player. The caller must hold gs.mu.
+func (gs *gameState) free(seat int) bool {
+	return gs.seats[seat] == "" && !gs.heldLocked(seat) && (cfg.Bot == "" || seat != botSeat)
+}
+
+// freeSeat returns the first free seat, or -1. The caller must hold gs.mu.
+func (gs *gameState) freeSeat() int {
+	for i := range gs.seats {
+		if gs.free(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// vacant reports the free seats. The caller must hold gs.mu.
+func (gs *gameState) vacant() [2]bool {
+	return [2]bool{gs.free(0), gs.free(1)}
+}
+
+// Watch counts in a new spectator, unless -maxspectators are watching
+// already.
+func (gs *gameState) Watch() bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if cfg.MaxSpectators > 0 && gs.spectators >= cfg.MaxSpectators {
+		return false
+	}
+	gs.spectators++
+	return true
+}
+
+// Sit moves the spectating session id with player p into a free seat and
+// returns it, or -1 if there is none.
+func (gs *gameState) Sit(id string, p player) int {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	seat := gs.freeSeat()
+	if seat < 0 || len(gs.queue) > 0 && gs.queue[0] != id {
+		return -1
+	}
+	gs.dequeueLocked(id)
+	gs.seats[seat] = id
+	gs.spectators--
+	gs.seatLocked(id, p, seat)
+	return seat
+}
+
+// seatLocked puts p, the player of the session id, into seat, which id
+// holds, and tells the sessions. The caller must hold gs.mu.
+func (gs *gameState) seatLocked(id string, p player, seat int) {
+	p.score = gs.m.players[seat].score
+	p.box = gs.sessions[id].box
+	gs.m.players[seat] = p
+	gs.broadcastLocked(joinedMsg{seat: seat, name: p.name})
+}
+
+// promotedLocked seats the spectating session of m in the seat promoteLocked
+// handed it, if any. The caller must hold gs.mu.
+func (gs *gameState) promotedLocked(m *model) {
+	if !m.spectator {
+		return
+	}
+	for seat, id := range gs.seats {
+		if id == m.session {
+			gs.seatLocked(id, m.viewer, seat)
+			m.sitDown(seat)
+		}
+	}
+}
+
+// Unseat frees seat for another player, keeping its name and score. The
+// player stays on as a spectator, even beyond -maxspectators.
+func (gs *gameState) Unseat(seat int) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.seats[seat] = ""
+	gs.spectators++
+	gs.m.players[seat].box = nil
+	gs.endSeriesLocked()
+	if gs.ready[seat] {
+		gs.ready[seat] = false
+		gs.broadcastLocked(readyMsg(gs.ready))
+	}
+	gs.broadcastLocked(leftMsg{seat: seat})
+	gs.promoteLocked()
+}
+
+// Rename gives the player seated by the session id the name, and returns a
+// command telling all sessions.
+func (gs *gameState) Rename(id, name string) tea.Cmd {
+	return func() tea.Msg {
+		gs.mu.Lock()
+		defer gs.mu.Unlock()
+		for i := range gs.seats {
+			if gs.seats[i] == id && gs.m.players[i].name != name {
+				from := gs.m.players[i].name
+				gs.m.players[i].name = name
+				gs.broadcastLocked(renamedMsg{seat: i, from: from, name: name})
+			}
+		}
+		return nil
+	}
+}
+
+// leaveSeat turns the session's player into a spectator. Nobody is waiting
+// to take over a game in progress, so leaving it forfeits it.
+func (m *model) leaveSeat() tea.Cmd {
+	var forfeit tea.Cmd
+	if other := 1 - m.self; !m.gameOver && len(m.record.moves) > 0 {
+		m.gameOver = true
+		m.winner = piece(other)
+		m.record.won = winResign
+		m.players[other].score++
+		forfeit = m.finish()
+	}
+	m.gs.Unseat(m.self)
+	m.viewer = m.players[m.self]
+	m.viewer.box = nil
+	m.spectator = true
+	m.joinLobby()
+	return forfeit
+}
+
+// takeSeat seats the spectating session in a free seat.
+func (m *model) takeSeat() tea.Cmd {
+	seat := m.gs.Sit(m.session, m.viewer)
+	if seat < 0 {
+		m.notice = m.tr("noSeat")
+		return nil
+	}
+	m.sitDown(seat)
+	return nil
+}
+
+// sitDown has the spectating session play in seat, given to it by gs.
+func (m *model) sitDown(seat int) {
+	score := m.players[seat].score
+	m.players[seat] = m.viewer
+	m.players[seat].score = score
+	m.self = seat
+	m.spectator = false
+	m.notice = ""
+	m.leaveLobby()
+}
+
+// promoteMsg tells the first spectator in line that promoteLocked handed
+// it a seat, to pick up with Sync.
+type promoteMsg struct{}
+
+// Enqueue puts the spectating session id in line for a seat and returns
+// its place, 1 being next.
+func (gs *gameState) Enqueue(id string) int {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.queue = append(gs.queue, id)
+	return len(gs.queue)
+}
+
+// dequeueLocked takes the session id out of the line. The caller must hold
+// gs.mu.
+func (gs *gameState) dequeueLocked(id string) {
+	for i, q := range gs.queue {
+		if q == id {
+			gs.queue = append(gs.queue[:i], gs.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// promoteLocked hands a free seat to the first spectator in line. It is
+// theirs at once, so nobody else takes it, and their session sits down in
+// it on its next Sync, see promotedLocked. The caller must hold gs.mu.
+func (gs *gameState) promoteLocked() {
+	seat := gs.freeSeat()
+	if len(gs.queue) == 0 || seat < 0 {
+		return
+	}
+	id := gs.queue[0]
+	gs.dequeueLocked(id)
+	sess, ok := gs.sessions[id]
+	if !ok {
+		return
+	}
+	gs.seats[seat] = id
+	gs.spectators--
+	gs.m.players[seat] = player{score: gs.m.players[seat].score, box: sess.box}
+	sess.box.post(promoteMsg{})
+}
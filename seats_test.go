@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// newQueueGame returns a game queueing the spectators for a seat, with
+// alice and bob seated and carol first in line.
+func newQueueGame(t *testing.T) (g *testGame, alice, bob, carol *testSession) {
+	g = newTestGame(t)
+	cfg.Spectators = true
+	cfg.WhenFull = "queue"
+	alice, bob = g.connect("alice"), g.connect("bob")
+	carol = g.connect("carol")
+	return g, alice, bob, carol
+}
+
+func TestPromotionHandsTheSeatOver(t *testing.T) {
+	g, alice, bob, carol := newQueueGame(t)
+	bob.update(keyMsg("v"))
+	// the seat is carol's before her session heard of it
+	if g.gs.seats[1] != carol.id {
+		t.Fatalf("seat 2 is held by %q, want carol's session %q", g.gs.seats[1], carol.id)
+	}
+	dave := g.connect("dave")
+	if !dave.m.spectator {
+		t.Fatalf("dave took the seat handed to carol")
+	}
+	g.settle()
+	if carol.m.spectator || carol.m.self != 1 {
+		t.Fatalf("carol spectates %v in seat %d after the promotion, want seat 2", carol.m.spectator, carol.m.self)
+	}
+	if got := alice.m.players[1].name; got != "carol" {
+		t.Errorf("alice plays %q, want carol", got)
+	}
+	alice.press("q")
+	carol.press("w")
+	if g.gs.m.board[0][1] != -1 {
+		t.Errorf("carol can't play the seat:\n%s", carol.view())
+	}
+}
+
+func TestPromotionNeedsNoMessage(t *testing.T) {
+	g, _, bob, carol := newQueueGame(t)
+	bob.update(keyMsg("v"))
+	// lose everything the promotion sent carol, any later sync seats her
+	for len(carol.inbox) > 0 {
+		<-carol.inbox
+	}
+	carol.update(redraw())
+	if carol.m.spectator || g.gs.m.players[1].name != "carol" {
+		t.Errorf("carol isn't seated by a redraw after the promotion")
+	}
+}
+
+func TestConcurrentJoins(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		gs := newGameState()
+		var wg sync.WaitGroup
+		seats := make([]int, 50)
+		start := make(chan struct{})
+		for i := range seats {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				id := fmt.Sprint("session-", i)
+				seats[i], _ = gs.Join(id, player{name: id, identity: id})
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+		count := map[int]int{}
+		for _, s := range seats {
+			count[s]++
+		}
+		if count[0] != 1 || count[1] != 1 || count[-1] != len(seats)-2 {
+			t.Fatalf("round %d: the joins got seats %v, want one seat 1, one seat 2 and no seat for the rest", round, count)
+		}
+		for i, s := range seats {
+			if s >= 0 && gs.seats[s] != fmt.Sprint("session-", i) {
+				t.Errorf("round %d: seat %d is held by %q, not the session told it got it", round, s+1, gs.seats[s])
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shareView is the position of the game in plain text, without styles or
+// box drawing so that it copies cleanly out of the terminal: the board in
+// the encoding of parseBoard, the turn or the result, and the scores, in
+// the language of the session.
+func (m model) shareView() string {
+	lines := []string{
+		fmt.Sprintf(m.tr("shareGame"), cfg.ServerName, m.record.id, len(m.record.moves)),
+		fmt.Sprintf(m.tr("shareBoard"), formatBoard(m.board)),
+	}
+	switch {
+	case m.gameOver && m.winner != 0:
+		lines = append(lines, fmt.Sprintf(m.tr("shareWon"), boardRunes[m.winner], m.players[seat(m.winner)].name))
+	case m.gameOver:
+		lines = append(lines, m.tr("shareDrawn"))
+	default:
+		lines = append(lines, fmt.Sprintf(m.tr("shareTurn"), boardRunes[m.currentPlayer], m.players[seat(m.currentPlayer)].name))
+	}
+	lines = append(lines,
+		fmt.Sprintf(m.tr("shareScore"), m.players[0].name, m.players[0].score, m.players[1].name, m.players[1].score),
+		"",
+		m.tr("shareHelp"),
+	)
+	return strings.Join(lines, "\n")
+}
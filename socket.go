@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+
+	"github.com/charmbracelet/log"
+)
+
+// listenSocket listens on the Unix socket at path instead of host and
+// port, see -socket. A socket left behind by a server that is gone is
+// removed first. One that still answers belongs to a running server, and
+// anything else at path isn't a socket to replace, so both are errors.
+func listenSocket(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if c, err := net.Dial("unix", path); err == nil {
+			c.Close()
+			return nil, fmt.Errorf("%s is in use by a running server", path)
+		}
+		log.Warn("Removing stale socket", "path", path)
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// removeSocket removes the socket of -socket once the server stopped, in
+// case closing its listener didn't.
+func removeSocket(path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Error("Could not remove socket", "path", path, "error", err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/charmbracelet/log"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteStore keeps the records in a SQLite database, one row per
+// identity and another per way it won, updated in place rather than
+// rewritten after every game like the JSON file.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// openSQLiteStore opens the database at path, creating it and its tables if
+// missing.
+func openSQLiteStore(path string) (Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite takes one writer at a time; a single connection queues them
+	// here rather than failing them with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS players (
+		id     TEXT PRIMARY KEY,
+		name   TEXT NOT NULL,
+		wins   INTEGER NOT NULL DEFAULT 0,
+		losses INTEGER NOT NULL DEFAULT 0,
+		draws  INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE IF NOT EXISTS wins (
+		id     TEXT NOT NULL REFERENCES players (id),
+		reason TEXT NOT NULL,
+		count  INTEGER NOT NULL,
+		PRIMARY KEY (id, reason)
+	)`); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+// RecordResult implements Store, adding the game to both players in one
+// transaction.
+func (st *sqliteStore) RecordResult(ids, names [2]string, winner int, won winReason) error {
+	tx, err := st.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i, id := range ids {
+		if id == "" {
+			continue
+		}
+		var wins, lost, drawn int
+		switch winner {
+		case -1:
+			drawn = 1
+		case i:
+			wins = 1
+		default:
+			lost = 1
+		}
+		if _, err := tx.Exec(`INSERT INTO players (id, name, wins, losses, draws) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET name = excluded.name,
+				wins = wins + excluded.wins, losses = losses + excluded.losses, draws = draws + excluded.draws`,
+			id, names[i], wins, lost, drawn); err != nil {
+			return err
+		}
+		if wins == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO wins (id, reason, count) VALUES (?, ?, 1)
+			ON CONFLICT (id, reason) DO UPDATE SET count = count + 1`, id, won); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// TopPlayers implements Store. A failed query is logged and lists no one.
+func (st *sqliteStore) TopPlayers(n int) []playerScore {
+	// a row per player and way they won, the players in order
+	rows, err := st.db.Query(`SELECT p.id, p.name, p.wins, p.losses, p.draws, w.reason, w.count
+		FROM (SELECT * FROM players ORDER BY wins DESC, losses ASC, id ASC LIMIT ?) p
+		LEFT JOIN wins w ON w.id = p.id
+		ORDER BY p.wins DESC, p.losses ASC, p.id ASC`, n)
+	if err != nil {
+		log.Error("Could not list the players", "error", err)
+		return nil
+	}
+	defer rows.Close()
+	var top []playerScore
+	for rows.Next() {
+		var ps playerScore
+		var reason sql.NullString
+		var count sql.NullInt64
+		if err := rows.Scan(&ps.ID, &ps.Name, &ps.Wins, &ps.Losses, &ps.Draws, &reason, &count); err != nil {
+			log.Error("Could not list the players", "error", err)
+			return top
+		}
+		if len(top) == 0 || top[len(top)-1].ID != ps.ID {
+			top = append(top, ps)
+		}
+		if reason.Valid {
+			last := &top[len(top)-1]
+			if last.WinsBy == nil {
+				last.WinsBy = map[winReason]int{}
+			}
+			last.WinsBy[winReason(reason.String)] = int(count.Int64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("Could not list the players", "error", err)
+	}
+	return top
+}
+
+// GetPlayer implements Store. A failed query is logged and finds no one.
+func (st *sqliteStore) GetPlayer(id string) (playerScore, bool) {
+	ps := playerScore{ID: id}
+	err := st.db.QueryRow(`SELECT name, wins, losses, draws FROM players WHERE id = ?`, id).
+		Scan(&ps.Name, &ps.Wins, &ps.Losses, &ps.Draws)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Error("Could not read the player", "id", id, "error", err)
+		}
+		return ps, false
+	}
+	rows, err := st.db.Query(`SELECT reason, count FROM wins WHERE id = ?`, id)
+	if err != nil {
+		log.Error("Could not read the player", "id", id, "error", err)
+		return ps, true
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var reason winReason
+		var count int
+		if err := rows.Scan(&reason, &count); err != nil {
+			log.Error("Could not read the player", "id", id, "error", err)
+			return ps, true
+		}
+		if ps.WinsBy == nil {
+			ps.WinsBy = map[winReason]int{}
+		}
+		ps.WinsBy[reason] = count
+	}
+	return ps, true
+}
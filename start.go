@@ -0,0 +1,32 @@
+package main
+
+// views are the first views selectable with -startview.
+var views = map[string]int{
+	"name":  0,
+	"board": 1,
+	"menu":  2,
+}
+
+// openStartView shows the view of -startview to the session just
+// connected. Spectators have no name to enter and watch the board instead.
+func (m *model) openStartView() {
+	switch v := views[cfg.StartView]; {
+	case v == 0 && m.spectator:
+		m.view = 1
+	case v == 0:
+		m.openNameEntry()
+	case v == 2:
+		m.openMenu()
+	default:
+		m.view = v
+	}
+}
+
+// openNameEntry asks the player for the name to play under, the current
+// one filled in for editing.
+func (m *model) openNameEntry() {
+	m.view = 0
+	m.focus = focusName
+	m.textInput.SetValue(m.me().name)
+	m.textInput.CursorEnd()
+}
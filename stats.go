@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// winReason tells how a game was won.
+type winReason string
+
+const (
+	winLine       winReason = "line"       // the winner completed a line
+	winResign     winReason = "resign"     // the loser gave up their seat
+	winDisconnect winReason = "disconnect" // the loser didn't come back in time, see -grace
+	winTimeout    winReason = "timeout"    // the loser ran out of time, see -turnexpiry
+	winLimit      winReason = "limit"      // the winner held more cells, see -onlimit
+)
+
+// winReasons lists the reasons in the order of the stats.
+var winReasons = []winReason{winLine, winResign, winDisconnect, winTimeout, winLimit}
+
+// stats counts what the server did since it started, for `ssh host stats`.
+type stats struct {
+	started time.Time
+	games   int               // games finished
+	wins    map[winReason]int // games won, the rest were drawn
+	peak    int               // most sessions connected at once
+}
+
+// Stats summarises the server: its uptime, the games played and in play,
+// and the sessions connected now and at the peak.
+func (gs *gameState) Stats() string {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	active := 0
+	if len(gs.m.record.moves) > 0 && !gs.m.gameOver {
+		active = 1
+	}
+	wins := ""
+	for i, r := range winReasons {
+		if i > 0 {
+			wins += ", "
+		}
+		wins += fmt.Sprintf("%d by %s", gs.stats.wins[r], r)
+	}
+	return fmt.Sprintf("%s\nuptime    %s\ngames     %d played, %d active\nwins      %s\nsessions  %d connected, %d at peak",
+		cfg.ServerName, time.Since(gs.stats.started).Round(time.Second),
+		gs.stats.games, active, wins, len(gs.sessions), gs.stats.peak)
+}
+
+// statsMiddleware answers the command stats with the summary of the server,
+// top with its best players and csv with the records of all of them, see
+// scoresCSV, and closes the session. It needs no
+// terminal, so ssh host stats works from scripts.
+func statsMiddleware(next ssh.Handler) ssh.Handler {
+	return func(s ssh.Session) {
+		if cmd := s.Command(); len(cmd) == 1 {
+			switch cmd[0] {
+			case "stats":
+				wish.Println(s, state.Stats())
+				return
+			case "top":
+				wish.Println(s, topPlayers())
+				return
+			case "csv":
+				if err := scoresCSV(s); err != nil {
+					log.Error("Could not export the scores", "error", err)
+				}
+				return
+			}
+		}
+		next(s)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWinReasons(t *testing.T) {
+	for _, tt := range []struct {
+		reason winReason
+		setup  func()
+		play   func(g *testGame, alice, bob *testSession)
+		winner string
+	}{
+		{winLine, nil, func(g *testGame, alice, bob *testSession) {
+			play(alice, bob, "q", "w", "a", "s", "z")
+		}, "alice"},
+		{winResign, nil, func(g *testGame, alice, bob *testSession) {
+			play(alice, bob, "q", "w")
+			alice.press("v")
+		}, "bob"},
+		{winDisconnect, func() { cfg.Grace = duration(10 * time.Millisecond) }, func(g *testGame, alice, bob *testSession) {
+			play(alice, bob, "q", "w")
+			alice.disconnect()
+			g.waitGrace()
+			g.settle()
+		}, "bob"},
+		{winTimeout, func() {
+			cfg.TurnTimeout = duration(time.Millisecond)
+			cfg.TurnExpiry = "forfeit"
+		}, func(g *testGame, alice, bob *testSession) {
+			// the first tick starts the turn, the second ends it
+			alice.update(tickMsg(time.Now()))
+			alice.update(tickMsg(time.Now()))
+			g.settle()
+		}, "bob"},
+		{winLimit, func() {
+			cfg.MoveLimit = 3
+			cfg.OnLimit = "pieces"
+		}, func(g *testGame, alice, bob *testSession) {
+			play(alice, bob, "q", "w", "s")
+		}, "alice"},
+	} {
+		t.Run(string(tt.reason), func(t *testing.T) {
+			g := newTestGame(t)
+			if tt.setup != nil {
+				tt.setup()
+			}
+			alice := g.connect("alice")
+			bob := g.connect("bob")
+			tt.play(g, alice, bob)
+			if !g.gs.m.gameOver || g.gs.m.record.won != tt.reason {
+				t.Fatalf("gameOver %v won %q, want a game won by %s", g.gs.m.gameOver, g.gs.m.record.won, tt.reason)
+			}
+			ps, ok := scores.GetPlayer("user:" + tt.winner)
+			if !ok || ps.Wins != 1 || ps.WinsBy[tt.reason] != 1 {
+				t.Errorf("%s's record is %+v, want a win by %s", tt.winner, ps, tt.reason)
+			}
+			if n := g.gs.stats.wins[tt.reason]; n != 1 {
+				t.Errorf("the stats count %d wins by %s, want 1", n, tt.reason)
+			}
+		})
+	}
+}
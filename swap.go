@@ -0,0 +1,43 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// swapMsg tells the sessions that the players swapped seats by the pie rule.
+type swapMsg struct{}
+
+// canSwap reports whether the session's player may take over the first move
+// instead of answering it, see -swaprule.
+func (m model) canSwap() bool {
+	// the bot keeps its seat
+	return cfg.SwapRule && cfg.Bot == "" && !m.spectator && !m.gameOver && !m.record.swapped &&
+		len(m.record.moves) == 1 && m.record.placed == 0 && seat(m.currentPlayer) == m.self
+}
+
+// Swap exchanges the seats of both players, their names and scores along with
+// them, and returns a command telling all sessions. The pie rule is checked
+// again on the game itself, so that a second swap racing with the first,
+// or with the answer to the first move, does nothing and returns nil.
+func (gs *gameState) Swap() tea.Cmd {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if r := gs.m.record; len(r.moves) != 1 || r.swapped || r.placed != 0 || gs.m.gameOver {
+		return nil
+	}
+	gs.seats[0], gs.seats[1] = gs.seats[1], gs.seats[0]
+	gs.m.swapSeats()
+	// the players keep their clocks
+	gs.clocks[0], gs.clocks[1] = gs.clocks[1], gs.clocks[0]
+	gs.ready[0], gs.ready[1] = gs.ready[1], gs.ready[0]
+	return gs.broadcast(swapMsg{})
+}
+
+// swapSeats exchanges the players of m, keeping the session on its player.
+func (m *model) swapSeats() {
+	m.players[0], m.players[1] = m.players[1], m.players[0]
+	m.record.scores[0], m.record.scores[1] = m.record.scores[1], m.record.scores[0]
+	m.record.swapped = true
+	m.ready[0], m.ready[1] = m.ready[1], m.ready[0]
+	if !m.spectator {
+		m.self = 1 - m.self
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestSwapOnlyOnce(t *testing.T) {
+	g := newTestGame(t)
+	cfg.SwapRule = true
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	alice.press("q")
+	// the second press comes before bob's session heard of the swap
+	bob.update(keyMsg("y"))
+	bob.update(keyMsg("y"))
+	g.settle()
+	if g.gs.seats[0] != bob.id || g.gs.seats[1] != alice.id {
+		t.Fatalf("seats are %q, want bob's session first", g.gs.seats)
+	}
+	if bob.m.self != 0 || alice.m.self != 1 {
+		t.Errorf("bob sits in %d and alice in %d, want 0 and 1", bob.m.self, alice.m.self)
+	}
+	for _, s := range []*testSession{alice, bob, {m: g.gs.m}} {
+		if s.m.players[0].name != "bob" || s.m.players[1].name != "alice" {
+			t.Errorf("players are %q and %q, want bob and alice", s.m.players[0].name, s.m.players[1].name)
+		}
+	}
+	if cmd := g.gs.Swap(); cmd != nil {
+		t.Error("the game swapped a second time")
+	}
+}
+
+func TestSwapAfterAnswerDoesNothing(t *testing.T) {
+	g := newTestGame(t)
+	cfg.SwapRule = true
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	alice.press("q")
+	bob.press("w")
+	if cmd := g.gs.Swap(); cmd != nil || g.gs.seats[0] != alice.id {
+		t.Error("the game swapped after the first move was answered")
+	}
+}
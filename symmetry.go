@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// symmetry maps the cell x, y of an n×n board to its image.
+type symmetry func(n, x, y int) (int, int)
+
+// symmetries are the eight rotations and reflections of a square board,
+// the identity first.
+var symmetries = []symmetry{
+	func(n, x, y int) (int, int) { return x, y },
+	func(n, x, y int) (int, int) { return y, n - 1 - x },
+	func(n, x, y int) (int, int) { return n - 1 - x, n - 1 - y },
+	func(n, x, y int) (int, int) { return n - 1 - y, x },
+	func(n, x, y int) (int, int) { return x, n - 1 - y },
+	func(n, x, y int) (int, int) { return n - 1 - x, y },
+	func(n, x, y int) (int, int) { return y, x },
+	func(n, x, y int) (int, int) { return n - 1 - y, n - 1 - x },
+}
+
+// square reports whether b has as many columns in every row as it has rows,
+// the boards symmetries apply to.
+func square(b board) bool {
+	for _, row := range b {
+		if len(row) != len(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// transform returns the image of b under s.
+func transform(b board, s symmetry) board {
+	t := b.clone()
+	for x := range b {
+		for y := range b[x] {
+			tx, ty := s(len(b), x, y)
+			t[tx][ty] = b[x][y]
+		}
+	}
+	return t
+}
+
+// lineSymmetries returns the symmetries of an n×n board mapping the winning
+// lines onto themselves, the identity first. Under the others a position
+// and its image may not score the same: custom lines, see -lines, may keep
+// none but the identity.
+func lineSymmetries(n int) []symmetry {
+	want := lineSet(winLines, n, symmetries[0])
+	kept := []symmetry{symmetries[0]}
+	for _, s := range symmetries[1:] {
+		if lineSet(winLines, n, s) == want {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// lineSet encodes the images of lines under s, in an order independent of
+// that of the lines and their cells.
+func lineSet(lines []line, n int, s symmetry) string {
+	set := make([]string, 0, len(lines))
+	for _, l := range lines {
+		cells := make([]string, 0, len(l))
+		for _, c := range l {
+			x, y := s(n, c[0], c[1])
+			cells = append(cells, fmt.Sprint(x, y))
+		}
+		sort.Strings(cells)
+		set = append(set, strings.Join(cells, ","))
+	}
+	sort.Strings(set)
+	return strings.Join(set, "|")
+}
+
+// canonical returns the key shared by b and all the positions equivalent to
+// it under syms: the least of their formatBoard encodings. Boards that
+// aren't square are only equivalent to themselves.
+func canonical(b board, syms []symmetry) string {
+	key := formatBoard(b)
+	if !square(b) {
+		return key
+	}
+	for _, s := range syms {
+		if k := formatBoard(transform(b, s)); k < key {
+			key = k
+		}
+	}
+	return key
+}
+
+// searched holds the scores of the positions a search went through, by
+// their canonical key under the symmetries keeping the winning lines, as
+// equivalent positions score the same.
+type searched struct {
+	syms   []symmetry
+	scores map[string]int
+}
+
+// newSearched returns an empty record for searching positions of boards
+// shaped like b.
+func newSearched(b board) *searched {
+	return &searched{syms: lineSymmetries(len(b)), scores: map[string]int{}}
+}
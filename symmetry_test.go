@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestCanonicalSymmetries(t *testing.T) {
+	for _, pos := range []string{"ox.|..x|...", "o..|...|...", ".o.|.x.|...", "oxo|x.o|..x"} {
+		b, err := parseBoard(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := canonical(b, symmetries)
+		images := map[string]bool{}
+		for i, s := range symmetries {
+			img := transform(b, s)
+			images[formatBoard(img)] = true
+			if got := canonical(img, symmetries); got != key {
+				t.Errorf("%s under symmetry %d is %s, keyed %s, want %s", pos, i, formatBoard(img), got, key)
+			}
+		}
+		if pos == "ox.|..x|..." && len(images) != len(symmetries) {
+			t.Errorf("the symmetries make %d distinct images of %s, want %d", len(images), pos, len(symmetries))
+		}
+	}
+	// the corner and the edge aren't equivalent
+	corner, _ := parseBoard("o..|...|...")
+	edge, _ := parseBoard(".o.|...|...")
+	if canonical(corner, symmetries) == canonical(edge, symmetries) {
+		t.Errorf("the corner and the edge openings share the key %s", canonical(corner, symmetries))
+	}
+}
+
+func TestLineSymmetries(t *testing.T) {
+	saved := winLines
+	defer func() { winLines = saved }()
+	for _, tt := range []struct {
+		name  string
+		lines []line
+		kept  int
+	}{
+		{"standard", winRules["standard"], 8},
+		{"nodiagonals", winRules["nodiagonals"], 8},
+		{"diagonals", winRules["diagonals"], 8},
+		// mirrored left to right only
+		{"top row", []line{{{0, 0}, {0, 1}, {0, 2}}}, 2},
+		{"corners", []line{{{0, 0}, {0, 2}}}, 2},
+		{"main diagonal", []line{diagonalLines[0]}, 4},
+		{"nothing symmetric", []line{{{0, 0}, {0, 1}}}, 1},
+	} {
+		winLines = tt.lines
+		if got := len(lineSymmetries(3)); got != tt.kept {
+			t.Errorf("%s: %d symmetries keep the lines, want %d", tt.name, got, tt.kept)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// takeback is a player's request to take back their last moves, pending
+// until the opponent agrees. No plies means no request.
+type takeback struct {
+	from  int // seat asking
+	plies int // number of recorded moves to take back
+}
+
+// takebackMsg tells the sessions about a new or withdrawn request.
+type takebackMsg struct {
+	takeback
+	declined bool
+}
+
+// RequestTakeback stores the request of seat from to take back plies moves
+// and returns a command telling all sessions.
+func (gs *gameState) RequestTakeback(from int, plies int) tea.Cmd {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.takeback = takeback{from: from, plies: plies}
+	return gs.broadcast(takebackMsg{takeback: gs.takeback})
+}
+
+// ClearTakeback drops a pending request, as declined if the opponent
+// refused it. It returns nil if there was none.
+func (gs *gameState) ClearTakeback(declined bool) tea.Cmd {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.takeback.plies == 0 {
+		return nil
+	}
+	gs.takeback = takeback{}
+	return gs.broadcast(takebackMsg{declined: declined})
+}
+
+// undoable is how many of the last moves can be taken back: all of them
+// unless capped by -history.
+func (m model) undoable() int {
+	if cfg.History > 0 && cfg.History < len(m.record.moves) {
+		return cfg.History
+	}
+	return len(m.record.moves)
+}
+
+// askTakeback asks the opponent to take back one more move than the
+// session's pending request, up to the whole game or -history, unless
+// -takebacks is off.
+func (m *model) askTakeback() tea.Cmd {
+	if !cfg.Takebacks {
+		m.notice = m.tr("takebackOff")
+		return nil
+	}
+	plies := 1
+	if m.takeback.plies > 0 && m.takeback.from == m.self {
+		plies = m.takeback.plies + 1
+	}
+	if plies > m.undoable() {
+		m.notice = m.tr("takebackNone")
+		return nil
+	}
+	// the bot doesn't mind
+	if cfg.Bot != "" {
+		m.takeback = takeback{from: m.self, plies: plies}
+		return m.acceptTakeback()
+	}
+	return m.gs.RequestTakeback(m.self, plies)
+}
+
+// acceptTakeback rewinds the game as requested by the opponent.
+func (m *model) acceptTakeback() tea.Cmd {
+	plies := m.takeback.plies
+	if plies > m.undoable() {
+		plies = m.undoable()
+	}
+	m.rewind(plies)
+	return tea.Batch(m.gs.Commit(*m), m.gs.ClearTakeback(false))
+}
+
+// rewind takes back the last n recorded moves of the game by replaying the
+// ones before them on an empty board. Scores, turn and the result follow
+// from the replay, so taking back the winning move reopens the game.
+func (m *model) rewind(n int) {
+	moves := m.record.moves[:len(m.record.moves)-n]
+	m.record.moves = nil
+	m.board = newBoard()
+	m.currentPlayer = m.record.first
+	m.players[0].score, m.players[1].score = m.record.scores[0], m.record.scores[1]
+	m.gameOver = false
+	m.winner = 0
+	m.record.won = ""
+	m.record.placed = 0
+	m.resetIn = 0
+	for _, mv := range moves {
+		updateCell(m, mv.x, mv.y)
+		m.record.moves[len(m.record.moves)-1].at = mv.at
+	}
+}
+
+// takebackLine describes the pending request to the session.
+func (m model) takebackLine() string {
+	if m.takeback.from == m.self && !m.spectator {
+		return fmt.Sprintf(m.tr("takebackAsked"), m.takeback.plies)
+	}
+	return fmt.Sprintf(m.tr("takebackOffer"), m.players[m.takeback.from].name, m.takeback.plies)
+}
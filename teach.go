@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lesson is what the loser of a game is taught after it, see -teach: the
+// first of their moves that turned a position holding at least a draw into
+// a lost one, and the best move in its place, as the minimax bot sees it.
+type lesson struct {
+	game    string
+	move    int    // number of the losing move, counted from 1 like the recap
+	before  board  // the position the losing move was played in
+	played  [2]int // cell of the losing move
+	better  [2]int // cell of the best move instead
+	winning bool   // the best move wins, rather than holding the draw
+}
+
+// lessonMsg is the lesson for the loser's session, worked out in the
+// background as searching every position of the game takes a while.
+type lessonMsg lesson
+
+// teach works out the lesson of the last finished game for the session,
+// if it lost the game the result is for.
+func (m model) teach(msg resultMsg) tea.Cmd {
+	// the evaluator has the players take turns one cell at a time
+	if !cfg.Teach || cfg.DoubleMove != "off" || m.spectator || msg.winner == 0 || seat(-msg.winner) != m.self {
+		return nil
+	}
+	r := m.gs.LastGame()
+	if r == nil || r.id != msg.game {
+		return nil
+	}
+	return func() tea.Msg {
+		l, ok := r.lesson(-msg.winner)
+		if !ok {
+			return nil
+		}
+		return lessonMsg(l)
+	}
+}
+
+// lesson replays the game for the losing piece loser and finds the move it
+// lost with. Moves on taken cells and passes aren't searched, as the bot
+// doesn't play them. There is no lesson when the game was lost before the
+// loser ever moved, or by something else than a move, like a timeout.
+func (r recap) lesson(loser int) (lesson, bool) {
+	// the score of a position depends on the player to move, which passes
+	// make the board alone not tell
+	g := model{board: newBoard(), currentPlayer: r.record.first}
+	seen := map[int]*searched{1: newSearched(g.board), -1: newSearched(g.board)}
+	for i, mv := range r.record.moves {
+		if g.currentPlayer == loser && mv.x != pass && g.board[mv.x][mv.y] == 0 {
+			before := g.board.clone()
+			if negamax(before, loser, seen[loser]) >= 0 {
+				after := before.clone()
+				after[mv.x][mv.y] = loser
+				if -negamax(after, -loser, seen[-loser]) < 0 {
+					x, y := minimaxMove(before, loser, nil, func(int) int { return 0 })
+					before[x][y] = loser
+					winning := -negamax(before, -loser, seen[-loser]) > 0
+					before[x][y] = 0
+					return lesson{
+						game:    r.id,
+						move:    i + 1,
+						before:  before,
+						played:  [2]int{mv.x, mv.y},
+						better:  [2]int{x, y},
+						winning: winning,
+					}, true
+				}
+			}
+		}
+		updateCell(&g, mv.x, mv.y)
+	}
+	return lesson{}, false
+}
+
+// lessonLines shows the loser the position of the losing move, the move
+// played faint and the better one highlighted, on the game over screen.
+func (m model) lessonLines(width int) string {
+	l := m.lesson
+	if !m.gameOver || l.before == nil || l.game != m.record.id {
+		return ""
+	}
+	p := m.me()
+	mine := piece(m.self)
+	id := "teachDraw"
+	if l.winning {
+		id = "teachWin"
+	}
+	grid := p.frame(func(x, y int) string {
+		glyph := string(p.glyph(mine))
+		switch {
+		case [2]int{x, y} == l.better && p.renderer != nil:
+			return p.txtStyle.Copy().Reverse(true).Render(glyph)
+		case [2]int{x, y} == l.better:
+			return glyph
+		case [2]int{x, y} == l.played:
+			return p.faint().Render(glyph)
+		}
+		return p.cell(l.before, x, y)
+	})
+	text := fmt.Sprintf(m.tr(id), l.move, keyLabel(p.keyScheme(), l.better[0], l.better[1]))
+	return "\n" + truncate(text, width) + "\n" + grid
+}
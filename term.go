@@ -0,0 +1,43 @@
+package main
+
+import "path"
+
+// asciiPieces replaces the pieces outside of ASCII.
+var asciiPieces = map[int]rune{
+	1:  'o',
+	-1: 'x',
+}
+
+// glyph returns the rune drawing the piece or line v for p.
+func (p player) glyph(v int) rune {
+	if r, ok := p.lineGlyph(v); ok {
+		return r
+	}
+	if r, ok := asciiPieces[v]; ok && p.ascii {
+		return r
+	}
+	if r, ok := symbolSets[p.symbolsName()][v]; ok {
+		return r
+	}
+	return pieces[v]
+}
+
+// termAllowed reports whether the terminal type term gets the full board,
+// as opposed to the ASCII one. Both lists hold path.Match patterns; a term is
+// allowed if -termallow is empty or it matches, unless it matches -termdeny.
+func termAllowed(term string) bool {
+	for _, pattern := range cfg.TermDeny {
+		if ok, _ := path.Match(pattern, term); ok {
+			return false
+		}
+	}
+	if len(cfg.TermAllow) == 0 {
+		return true
+	}
+	for _, pattern := range cfg.TermAllow {
+		if ok, _ := path.Match(pattern, term); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package main
+
+import "github.com/charmbracelet/lipgloss"
+
+// theme decorates the lines struck through the cells of a won game, see
+// -theme.
+type theme struct {
+	lines map[int]rune   // by the mark of the line, see line.mark
+	color lipgloss.Color // of the struck cells, "" for the text color
+	bold  bool
+}
+
+// classicLines strike through lines in ASCII. Terminals kept to ASCII by
+// -termallow and -termdeny get them whatever the theme.
+var classicLines = map[int]rune{
+	2: '-',
+	3: '|',
+	4: '\\',
+	5: '/',
+}
+
+// themes are the decorations selectable with -theme.
+var themes = map[string]theme{
+	"classic": {lines: classicLines},
+	"bold": {
+		lines: map[int]rune{2: '━', 3: '┃', 4: '╲', 5: '╱'},
+		bold:  true,
+	},
+	"strike": {
+		lines: map[int]rune{2: '═', 3: '║', 4: '╲', 5: '╱'},
+		color: "9",
+	},
+}
+
+// lineGlyph returns the rune striking through a cell of a line marked v
+// for p, and whether v marks a line at all.
+func (p player) lineGlyph(v int) (rune, bool) {
+	if p.ascii {
+		r, ok := classicLines[v]
+		return r, ok
+	}
+	r, ok := themes[p.themeName()].lines[v]
+	return r, ok
+}
+
+// lineCell draws r, striking through a cell, in the style of the theme.
+func (p player) lineCell(r rune) string {
+	t := themes[p.themeName()]
+	if p.renderer == nil || p.ascii || t.color == "" && !t.bold {
+		return string(r)
+	}
+	style := p.renderer.NewStyle().Bold(t.bold)
+	if t.color != "" {
+		style = style.Foreground(t.color)
+	}
+	return style.Render(string(r))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThemesDrawWinningLine(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	// alice takes the top row
+	play(alice, bob, "q", "a", "w", "s", "e")
+	views := map[string]string{}
+	for name, th := range themes {
+		alice.m.me().look.theme = name
+		v := alice.view()
+		if strings.Count(v, string(th.lines[2])) < 3 {
+			t.Errorf("%s: the row isn't struck through with %q:\n%s", name, th.lines[2], v)
+		}
+		for other, ov := range views {
+			if ov == v {
+				t.Errorf("%s draws the won game like %s:\n%s", name, other, v)
+			}
+		}
+		views[name] = v
+	}
+	// whatever the theme, ASCII terminals get the classic lines
+	alice.m.me().ascii = true
+	alice.m.me().look.theme = "classic"
+	classic := alice.view()
+	for name := range themes {
+		alice.m.me().look.theme = name
+		if v := alice.view(); v != classic {
+			t.Errorf("%s on an ASCII terminal isn't drawn classic:\n%s\nwant:\n%s", name, v, classic)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tickMsg is sent to every session once per cfg.TickRate. Animations and
+// countdowns count ticks instead of starting timers of their own, so the
+// number of redraws a session can cause stays bounded by the tick rate.
+type tickMsg time.Time
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Duration(cfg.TickRate), func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// ticks is the number of ticks lasting at least d, but at least one.
+func ticks(d time.Duration) int {
+	rate := time.Duration(cfg.TickRate)
+	n := int((d + rate - 1) / rate)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// seconds rounds n ticks up to whole seconds for display.
+func seconds(n int) int {
+	d := time.Duration(n) * time.Duration(cfg.TickRate)
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Move timing analysis flags players whose moves come too fast or too
+// evenly for a person, like a bot would play them, see -timingflag. It
+// only logs: nobody is kicked or banned for it.
+
+const (
+	// minTimed is the fewest moves of a player worth judging.
+	minTimed = 3
+	// fastMove is a think time no person keeps up for long.
+	fastMove = 300 * time.Millisecond
+	// evenSpread is the coefficient of variation of think times below
+	// which they are too even, people's easily varying by half.
+	evenSpread = 0.5
+)
+
+// thinkTimes returns how long the player of piece took for each of their
+// moves in r, from the move before. The first move of the game is left out:
+// it includes the wait for the game to start, and it was made from the
+// other seat if the players swapped.
+func thinkTimes(r recording, piece int) []time.Duration {
+	var times []time.Duration
+	p := r.first
+	if p == 0 {
+		p = 1
+	}
+	for i, mv := range r.moves {
+		if p == piece && i > 0 {
+			times = append(times, mv.at.Sub(r.moves[i-1].at))
+		}
+		p = -p
+	}
+	return times
+}
+
+// suspicion scores how bot-like the think times are, from 0 for nothing
+// unusual to 1: half for the share of moves faster than fastMove, half for
+// how much more even than evenSpread they are. Fewer than minTimed moves
+// score 0.
+func suspicion(times []time.Duration) float64 {
+	if len(times) < minTimed {
+		return 0
+	}
+	fast := 0
+	mean := 0.0
+	for _, t := range times {
+		if t < fastMove {
+			fast++
+		}
+		mean += float64(t)
+	}
+	mean /= float64(len(times))
+	variance := 0.0
+	for _, t := range times {
+		variance += (float64(t) - mean) * (float64(t) - mean)
+	}
+	variance /= float64(len(times))
+	even := 1.0
+	if mean > 0 {
+		even = math.Max(0, 1-math.Sqrt(variance)/mean/evenSpread)
+	}
+	return 0.5*float64(fast)/float64(len(times)) + 0.5*even
+}
+
+// checkTimings logs the players of the game g just finished whose think
+// times score -timingflag or more. The identities are those of the seated
+// players; the bot's seat has none and isn't judged.
+func checkTimings(seated [2]player, g model) {
+	if cfg.TimingFlag <= 0 {
+		return
+	}
+	for i, p := range seated {
+		if p.identity == "" {
+			continue
+		}
+		times := thinkTimes(g.record, piece(i))
+		score := suspicion(times)
+		if score < cfg.TimingFlag {
+			continue
+		}
+		least := times[0]
+		for _, t := range times[1:] {
+			if t < least {
+				least = t
+			}
+		}
+		log.Warn("Suspicious move timings", "game", g.record.id, "name", g.players[i].name, "identity", p.identity,
+			"score", math.Round(score*100)/100, "moves", len(times), "fastest", least.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+)
+
+// The XTWINOPS sequences saving and restoring the terminal title around
+// the session, see -title. Terminals without them just ignore them.
+const (
+	pushTitle = "\x1b[22;0t"
+	popTitle  = "\x1b[23;0t"
+)
+
+// saveTitle saves the terminal title of s before the session changes it.
+func saveTitle(s ssh.Session) {
+	if cfg.Title {
+		fmt.Fprint(s, pushTitle)
+	}
+}
+
+// restoreTitle puts back the terminal title of s saved by saveTitle.
+func restoreTitle(s ssh.Session) {
+	if cfg.Title {
+		fmt.Fprint(s, popTitle)
+	}
+}
+
+// windowTitle is the terminal title of the session: the server name and
+// the opponent, or both players for a spectator.
+func (m model) windowTitle() string {
+	switch {
+	case m.spectator && !m.vacant[0] && !m.vacant[1]:
+		return fmt.Sprintf("%s — %s vs %s", cfg.ServerName, m.players[0].name, m.players[1].name)
+	case m.spectator || m.vacant[1-m.self]:
+		return cfg.ServerName
+	}
+	return fmt.Sprintf("%s — %s", cfg.ServerName, m.players[1-m.self].name)
+}
+
+// tickTitle retitles the terminal whenever the opponent changes.
+func (m *model) tickTitle() tea.Cmd {
+	if !cfg.Title || m.windowTitle() == m.title {
+		return nil
+	}
+	m.title = m.windowTitle()
+	return tea.SetWindowTitle(m.title)
+}
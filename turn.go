@@ -0,0 +1,109 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// pass is the cell recorded for a turn skipped by the turn timeout.
+const pass = -1
+
+// turnTimer identifies the turn a session counts down, so that any move,
+// made here or synced from another session, restarts the countdown.
+type turnTimer struct {
+	game   time.Time
+	moves  int
+	player int
+}
+
+// gameClock is the time left to the player of a seat for their moves of
+// the game started at game, see -gameclock.
+type gameClock struct {
+	game time.Time
+	left int // ticks
+}
+
+// onTurn reports whether the session's player is the one to move.
+func (m model) onTurn() bool {
+	return !m.spectator && !m.gameOver && m.flip == 0 && !m.waiting() &&
+		seat(m.currentPlayer) == m.self
+}
+
+// tickTurn counts down the turn of the session's player, see -turntimeout,
+// and their game clock, see -gameclock.
+// Only the session on turn counts, so neither the opponent nor spectators
+// can run out anyone's time.
+func (m *model) tickTurn() tea.Cmd {
+	if !m.onTurn() {
+		m.turnLeft = 0
+		return nil
+	}
+	if cfg.GameClock > 0 {
+		m.clock = m.gs.TickClock(m.self, m.record.started)
+		if m.clock.left <= 0 {
+			if moveSample.keep() {
+				log.Info("Game clock ran out", "game", m.record.id, "player", m.me().name)
+			}
+			return m.loseOnTime()
+		}
+	}
+	if cfg.TurnTimeout <= 0 {
+		m.turnLeft = 0
+		return nil
+	}
+	t := turnTimer{game: m.record.started, moves: len(m.record.moves), player: m.currentPlayer}
+	if t != m.turn {
+		m.turn = t
+		m.turnLeft = ticks(time.Duration(cfg.TurnTimeout))
+		return nil
+	}
+	m.turnLeft--
+	if m.turnLeft > 0 {
+		return nil
+	}
+	if moveSample.keep() {
+		log.Info("Turn timed out", "game", m.record.id, "player", m.me().name, "expiry", cfg.TurnExpiry)
+	}
+	if cfg.TurnExpiry == "forfeit" {
+		return m.loseOnTime()
+	}
+	if !m.gs.Play(m, pass, pass) {
+		return nil
+	}
+	return m.finish()
+}
+
+// TickClock counts a tick off the game clock of seat in the game started
+// at game and returns it. The clocks are kept by gs rather than the
+// sessions, so that a player reconnecting finds theirs where it stopped.
+func (gs *gameState) TickClock(seat int, game time.Time) gameClock {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	c := &gs.clocks[seat]
+	if c.game != game {
+		*c = gameClock{game: game, left: ticks(time.Duration(cfg.GameClock))}
+	}
+	c.left--
+	return *c
+}
+
+// loseOnTime ends the game as lost by the player on turn, who ran out of
+// time.
+func (m *model) loseOnTime() tea.Cmd {
+	m.gameOver = true
+	m.winner = -m.currentPlayer
+	m.record.won = winTimeout
+	m.players[seat(m.winner)].score++
+	return m.finish()
+}
+
+// clockLeft is the time left on the game clock of the session's player, in
+// ticks, or 0 without -gameclock or off turn.
+func (m model) clockLeft() int {
+	if cfg.GameClock <= 0 || !m.onTurn() || m.clock.game != m.record.started {
+		return 0
+	}
+	return m.clock.left
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClockSurvivesReconnect(t *testing.T) {
+	g := newTestGame(t)
+	cfg.GameClock = duration(time.Minute)
+	cfg.Grace = duration(time.Minute)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	play(alice, bob, "q", "w")
+	full := ticks(time.Minute)
+	for i := 0; i < 10; i++ {
+		alice.m.tickTurn()
+	}
+	if left := alice.m.clockLeft(); left != full-10 {
+		t.Fatalf("alice has %d ticks left, want %d", left, full-10)
+	}
+	alice.disconnect()
+	again := g.connect("alice")
+	if again.m.self != 0 {
+		t.Fatalf("alice came back to seat %d, want her seat", again.m.self)
+	}
+	again.m.tickTurn()
+	if left := again.m.clockLeft(); left != full-11 {
+		t.Errorf("alice has %d ticks left after reconnecting, want %d", left, full-11)
+	}
+}
+
+func TestStrictTurns(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		g := newTestGame(t)
+		cfg.StrictTurns = strict
+		alice := g.connect("alice")
+		bob := g.connect("bob")
+		// bob presses on alice's turn
+		bob.press("q")
+		if played := g.gs.m.board[0][0] != 0; played == strict {
+			t.Errorf("strictturns %v: bob's press off turn played %v", strict, played)
+		}
+		if strict {
+			alice.press("q")
+			bob.press("w")
+			if g.gs.m.board[0][0] != 1 || g.gs.m.board[0][1] != -1 {
+				t.Errorf("the players can't play on their turns:\n%s", alice.view())
+			}
+		}
+	}
+}
+
+func TestCompetitiveLeavesUserSettings(t *testing.T) {
+	saved, savedArgs, savedScores := cfg, os.Args, scores
+	t.Cleanup(func() {
+		cfg, os.Args, scores = saved, savedArgs, savedScores
+		*configPath = ""
+	})
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"gameClock": "10m", "takebacks": true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg = defaultConfig()
+	os.Args = []string{"tiktakgo", "-config", path, "-competitive", "-turntimeout", "1m",
+		"-scores", filepath.Join(dir, "scores.json")}
+	if err := setupConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GameClock != duration(10*time.Minute) || !cfg.Takebacks {
+		t.Errorf("the preset overrode the file: gameclock %v, takebacks %v", cfg.GameClock, cfg.Takebacks)
+	}
+	if cfg.TurnTimeout != duration(time.Minute) {
+		t.Errorf("the preset overrode -turntimeout: %v", cfg.TurnTimeout)
+	}
+	if !cfg.StrictTurns || cfg.Hints != 0 || cfg.TurnExpiry != "forfeit" {
+		t.Errorf("the preset wasn't applied to the rest: strictturns %v, hints %d, turnexpiry %q",
+			cfg.StrictTurns, cfg.Hints, cfg.TurnExpiry)
+	}
+}
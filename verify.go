@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// castGame is the game behind an exported cast, saved in its header so
+// that the cast can be checked against it, see -verify. The frames alone
+// can't be, as they are the views of the moves, not the moves.
+type castGame struct {
+	ID      string    `json:"id"`
+	Players [2]string `json:"players"`
+	First   int       `json:"first"`  // piece moving first, 1 or -1
+	Moves   [][2]int  `json:"moves"`  // cells pressed in order, -1, -1 for a pass
+	Winner  int       `json:"winner"` // piece that won, 0 for a draw
+	Won     winReason `json:"won,omitempty"`
+	Hash    string    `json:"hash"` // see HashGame
+}
+
+// newCastGame records the game of m, which just finished, with its hash.
+func newCastGame(m model) *castGame {
+	g := &castGame{
+		ID:      m.record.id,
+		Players: [2]string{m.players[0].name, m.players[1].name},
+		First:   m.record.first,
+		Moves:   make([][2]int, len(m.record.moves)),
+		Winner:  m.winner,
+		Won:     m.record.won,
+	}
+	for i, mv := range m.record.moves {
+		g.Moves[i] = [2]int{mv.x, mv.y}
+	}
+	g.Hash = HashGame(*g)
+	return g
+}
+
+// HashGame returns the SHA-256 of the ID, the players, the moves in order
+// and the result of g, hex encoded. Only its own hash doesn't count.
+func HashGame(g castGame) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "id %q\nplayers %q %q\nfirst %d\n", g.ID, g.Players[0], g.Players[1], g.First)
+	for _, mv := range g.Moves {
+		fmt.Fprintf(h, "move %d %d\n", mv[0], mv[1])
+	}
+	fmt.Fprintf(h, "winner %d %q\n", g.Winner, g.Won)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verifyCast checks the cast at path against the hash of the game in its
+// header, failing if the game was changed since it was exported.
+func verifyCast(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && strings.TrimSpace(line) == "" {
+		return fmt.Errorf("no header: %w", err)
+	}
+	var header castHeader
+	if err := json.Unmarshal([]byte(line), &header); err != nil {
+		return fmt.Errorf("bad header: %w", err)
+	}
+	if header.Game == nil {
+		return errors.New("no game recorded in the header")
+	}
+	if HashGame(*header.Game) != header.Game.Hash {
+		return fmt.Errorf("game %s doesn't match its hash", header.Game.ID)
+	}
+	return nil
+}
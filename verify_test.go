@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCastVerifies(t *testing.T) {
+	g := newTestGame(t)
+	alice := g.connect("alice")
+	bob := g.connect("bob")
+	play(alice, bob, "q", "w", "a", "s", "z")
+	path := filepath.Join(t.TempDir(), "game.cast")
+	if err := exportCast(alice.m, path); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyCast(path); err != nil {
+		t.Fatalf("the exported cast doesn't verify: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frames []string
+	for _, line := range bytes.Split(bytes.TrimSpace(b), []byte("\n"))[1:] {
+		var ev [3]any
+		if err := json.Unmarshal(line, &ev); err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, stripANSI(ev[2].(string)))
+	}
+	if strings.Contains(frames[0], "alice wins!") {
+		t.Errorf("the first frame shows the result:\n%s", frames[0])
+	}
+	if !strings.Contains(frames[len(frames)-1], "alice wins!") {
+		t.Errorf("the last frame doesn't show the result:\n%s", frames[len(frames)-1])
+	}
+	for name, tamper := range map[string]func(*castGame){
+		"id":      func(g *castGame) { g.ID = "0002" },
+		"players": func(g *castGame) { g.Players[1] = "carol" },
+		"first":   func(g *castGame) { g.First = -g.First },
+		"move":    func(g *castGame) { g.Moves[4] = [2]int{2, 2} },
+		"dropped": func(g *castGame) { g.Moves = g.Moves[:4] },
+		"winner":  func(g *castGame) { g.Winner = -1 },
+		"won":     func(g *castGame) { g.Won = winTimeout },
+	} {
+		t.Run(name, func(t *testing.T) {
+			line, rest, _ := bytes.Cut(b, []byte("\n"))
+			var header castHeader
+			if err := json.Unmarshal(line, &header); err != nil {
+				t.Fatal(err)
+			}
+			tamper(header.Game)
+			line, err := json.Marshal(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tampered := filepath.Join(t.TempDir(), "tampered.cast")
+			if err := os.WriteFile(tampered, append(append(line, '\n'), rest...), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := verifyCast(tampered); err == nil {
+				t.Error("a cast with the game changed verifies")
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the views drawn")
+
+// golden compares the view got with testdata/<name>.golden, or with -update
+// writes it there.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run the test with -update to write it", err)
+	}
+	if got != string(want) {
+		t.Errorf("the view differs from %s, got:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestViewGolden(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		moves []string // alice first
+	}{
+		{"empty", nil},
+		{"midgame", []string{"q", "w", "a"}},
+		{"row", []string{"q", "a", "w", "s", "e"}},
+		{"column", []string{"q", "w", "a", "s", "z"}},
+		{"diagonal", []string{"q", "w", "s", "e", "c"}},
+		{"antidiagonal", []string{"e", "w", "s", "q", "z"}},
+		{"draw", []string{"q", "w", "e", "s", "a", "d", "x", "z", "c"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t)
+			alice := g.connect("alice")
+			bob := g.connect("bob")
+			play(alice, bob, tt.moves...)
+			golden(t, tt.name, alice.view())
+		})
+	}
+}
+
+func TestViewBeforeAnySession(t *testing.T) {
+	// the shared model, whose players have no terminal nor styles yet
+	g := newTestGame(t)
+	for _, view := range []int{0, 1, 2, 4} {
+		m := g.gs.m
+		m.view = view
+		var v string
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("view %d panics before any session connected: %v", view, r)
+				}
+			}()
+			v = stripANSI(m.View())
+		}()
+		if v == "" {
+			t.Errorf("view %d is empty before any session connected", view)
+		}
+	}
+	m := g.gs.m
+	m.view = 1
+	if v := m.View(); !strings.Contains(v, "┏") && !strings.Contains(v, "+") {
+		t.Errorf("the board isn't drawn before any session connected:\n%s", v)
+	}
+}
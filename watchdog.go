@@ -0,0 +1,70 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// expiredMsg tells the sessions that the game ran past -maxgame and was
+// ended as a draw, for its players to leave their seats.
+type expiredMsg struct {
+	game string
+}
+
+// maxSweep is the longest the watchdog waits between checks of the game.
+const maxSweep = time.Minute
+
+// watchGames checks the game in progress now and then, ending it once it
+// ran for -maxgame. Games are timed from their first move, so a board
+// nobody played on yet never expires.
+func (gs *gameState) watchGames() {
+	sweep := time.Duration(cfg.MaxGame) / 10
+	if sweep > maxSweep {
+		sweep = maxSweep
+	}
+	if sweep < time.Second {
+		sweep = time.Second
+	}
+	t := time.NewTicker(sweep)
+	defer t.Stop()
+	for now := range t.C {
+		gs.expire(now)
+	}
+}
+
+// expire ends the game in progress as a draw if it ran past -maxgame by
+// now, a safety net against games stuck for good. Seats held for dropped
+// players are freed along with it.
+func (gs *gameState) expire(now time.Time) {
+	defer gs.saveResults()
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	rec := gs.m.record
+	if len(rec.moves) == 0 || gs.m.gameOver || now.Sub(rec.moves[0].at) < time.Duration(cfg.MaxGame) {
+		return
+	}
+	log.Warn("Game ran too long, ending it as a draw", "game", rec.id, "moves", len(rec.moves),
+		"took", now.Sub(rec.moves[0].at).Round(time.Second), "max", cfg.MaxGame)
+	m := gs.m
+	m.board = gs.m.board.clone()
+	m.record = rec.clone()
+	m.gameOver = true
+	m.winner = 0
+	gs.commitLocked(m)
+	gs.held = [2]hold{}
+	gs.broadcastLocked(expiredMsg{game: rec.id})
+	gs.promoteLocked()
+}
+
+// leaveExpired tells the session the game expired and, if it played in
+// the game, makes it leave its seat.
+func (m *model) leaveExpired(msg expiredMsg) tea.Cmd {
+	m.gs.Sync(m)
+	m.announce(announceMsg{id: "expired", args: []interface{}{msg.game, cfg.MaxGame}})
+	if m.spectator {
+		return nil
+	}
+	return m.leaveSeat()
+}